@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -35,19 +36,50 @@ func main() {
 
 	// Initialize services
 	articleRepo := repository.NewArticleRepository(db)
-	articleService := service.NewArticleService(articleRepo)
+	tagRepo := repository.NewTagRepository(db)
 	feedRepo := repository.NewFeedRepository(db)
+	articleService := service.NewArticleService(articleRepo, tagRepo, feedRepo)
+	storyRepo := repository.NewStoryRepository(db)
+	storyService := service.NewStoryService(storyRepo)
 	rssReader := service.NewRssArticleReader(articleService)
-	feedService := service.NewFeedService(feedRepo, rssReader, articleService)
+	sources := service.NewSourceRegistry(rssReader)
+	ruleRepo := repository.NewRuleRepository(db)
+	ruleService := service.NewRuleService(ruleRepo, feedRepo, tagRepo, articleRepo)
+	feedService := service.NewFeedService(feedRepo, sources, articleService, storyService, ruleService)
+	rankingService := service.NewRankingService(articleRepo, feedRepo)
+	tagService := service.NewTagService(tagRepo, articleRepo, ruleService)
+	highlightRepo := repository.NewHighlightRepository(db)
+	highlightService := service.NewHighlightService(highlightRepo, articleRepo)
+	collectionRepo := repository.NewCollectionRepository(db)
+	collectionService := service.NewCollectionService(collectionRepo, articleRepo)
+	retentionRepo := repository.NewRetentionRepository(db)
+	retentionService := service.NewRetentionService(retentionRepo, feedRepo)
+	trashRepo := repository.NewTrashRepository(db)
+	trashService := service.NewTrashService(trashRepo, getTrashPurgeAfter())
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := service.NewCategoryService(categoryRepo, feedRepo, articleService)
+	digestService := service.NewDigestService(articleService, collectionService)
+	shareRepo := repository.NewShareRepository(db)
+	shareService := service.NewShareService(shareRepo, articleRepo, collectionRepo, feedRepo)
+	opmlService := service.NewOPMLService(feedService, categoryService)
+	statsRepo := repository.NewStatsRepository(db)
+	statsService := service.NewStatsService(statsRepo, feedRepo)
 
 	runMigrations(databaseUrl)
 	go startReadingRssFeeds(feedService)
-	go startDeleteOldArticlesJob(articleService)
+	go startCleanupJob(retentionService, trashService, storyService)
+	go startFeedValidationJob(feedService)
+	if digestDir := os.Getenv("EPUB_DIGEST_DIR"); digestDir != "" {
+		go startDigestJob(digestService, digestDir, getDigestTime())
+	}
 
-	startServer(articleService, feedService)
+	startServer(articleService, feedService, storyService, rankingService, tagService, categoryService, ruleService, highlightService, collectionService, retentionService, trashService, digestService, shareService, opmlService, statsService)
 }
 
-func startServer(articleService *service.ArticleService, feedService *service.FeedService) {
+// opmlTimeout is the timeout of the OPML routes, which gives an import time to validate up to 1000 feeds
+const opmlTimeout = 10 * time.Minute
+
+func startServer(articleService *service.ArticleService, feedService *service.FeedService, storyService *service.StoryService, rankingService *service.RankingService, tagService *service.TagService, categoryService *service.CategoryService, ruleService *service.RuleService, highlightService *service.HighlightService, collectionService *service.CollectionService, retentionService *service.RetentionService, trashService *service.TrashService, digestService *service.DigestService, shareService *service.ShareService, opmlService *service.OPMLService, statsService *service.StatsService) {
 	r := chi.NewRouter()
 
 	// A good base middleware stack
@@ -55,10 +87,31 @@ func startServer(articleService *service.ArticleService, feedService *service.Fe
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 
-	setupArticleHttpHandler(r, articleService)
-	setupFeedHttpHandler(r, feedService, articleService)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(60 * time.Second))
+
+		setupArticleHttpHandler(r, articleService, rankingService, tagService, highlightService, collectionService)
+		setupFeedHttpHandler(r, feedService, articleService)
+		setupStoryHttpHandler(r, storyService)
+		setupTagHttpHandler(r, tagService)
+		setupCategoryHttpHandler(r, categoryService)
+		setupTimelineHttpHandler(r, articleService)
+		setupRuleHttpHandler(r, ruleService)
+		setupHighlightHttpHandler(r, highlightService)
+		setupCollectionHttpHandler(r, collectionService)
+		setupRetentionHttpHandler(r, retentionService)
+		setupTrashHttpHandler(r, trashService)
+		setupExportHttpHandler(r, articleService, digestService)
+		setupShareHttpHandler(r, shareService)
+		setupStatsHttpHandler(r, statsService)
+	})
+	// Importing OPML validates every feed of the file, which takes longer than other requests
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(opmlTimeout))
+
+		setupOPMLHttpHandler(r, opmlService)
+	})
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -71,32 +124,192 @@ func startServer(articleService *service.ArticleService, feedService *service.Fe
 	}
 }
 
-func setupArticleHttpHandler(r *chi.Mux, articleService *service.ArticleService) {
+func setupArticleHttpHandler(r chi.Router, articleService *service.ArticleService, rankingService *service.RankingService, tagService *service.TagService, highlightService *service.HighlightService, collectionService *service.CollectionService) {
 	articleHandler := handler.NewArticleHandler(articleService)
+	rankingHandler := handler.NewRankingHandler(rankingService)
+	tagHandler := handler.NewTagHandler(tagService)
+	highlightHandler := handler.NewHighlightHandler(highlightService)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
 
 	r.Route("/api/articles", func(r chi.Router) {
 		r.Get("/", articleHandler.GetAll)
 		r.Get("/history", articleHandler.GetHistory)
 		r.Get("/saved", articleHandler.GetSaved)
+		r.Get("/search", articleHandler.Search)
+		r.Get("/for-you", rankingHandler.GetForYou)
+		r.Post("/bulk", articleHandler.Bulk)
+		r.Post("/mark-all-read", articleHandler.MarkAllRead)
 
 		r.Get("/{id}", articleHandler.GetByID)
 		r.Patch("/{id}/saved", articleHandler.UpdateSavedByID)
 		r.Patch("/{id}/read", articleHandler.UpdateReadByID)
+		r.Patch("/{id}/opened", articleHandler.UpdateOpenedByID)
+		r.Patch("/{id}/dwell", articleHandler.AddDwellByID)
+		r.Put("/{id}/tags/{tagId}", tagHandler.AddToArticle)
+		r.Delete("/{id}/tags/{tagId}", tagHandler.RemoveFromArticle)
+		r.Put("/{id}/note", highlightHandler.UpdateArticleNote)
+		r.Get("/{id}/highlights", highlightHandler.GetByArticleID)
+		r.Post("/{id}/highlights", highlightHandler.Create)
+		r.Put("/{id}/highlights/{highlightId}", highlightHandler.Update)
+		r.Delete("/{id}/highlights/{highlightId}", highlightHandler.Delete)
+		r.Get("/{id}/collections", collectionHandler.GetByArticleID)
 	})
 }
 
-func setupFeedHttpHandler(r *chi.Mux, feedService *service.FeedService, articleService *service.ArticleService) {
+func setupFeedHttpHandler(r chi.Router, feedService *service.FeedService, articleService *service.ArticleService) {
 	feedHandler := handler.NewFeedHandler(feedService)
 	articleHandler := handler.NewArticleHandler(articleService)
 
 	r.Route("/api/feeds", func(r chi.Router) {
 		r.Get("/", feedHandler.GetAll)
+		r.Get("/health", feedHandler.GetHealth)
 		r.Get("/{id}", feedHandler.GetByID)
 		r.Post("/", feedHandler.Create)
 		r.Put("/{id}", feedHandler.Update)
 		r.Delete("/{id}", feedHandler.Delete)
 		r.Patch("/{id}/read", feedHandler.UpdateLastReadAt)
+		r.Get("/{id}/new", feedHandler.GetNewArticles)
 		r.Get("/{feedId}/paginated", articleHandler.GetPaginatedByFeedID)
+		r.Post("/{feedId}/mark-read", articleHandler.MarkFeedRead)
+	})
+}
+
+func setupStoryHttpHandler(r chi.Router, storyService *service.StoryService) {
+	storyHandler := handler.NewStoryHandler(storyService)
+
+	r.Route("/api/stories", func(r chi.Router) {
+		r.Get("/", storyHandler.GetPaginated)
+	})
+}
+
+func setupTagHttpHandler(r chi.Router, tagService *service.TagService) {
+	tagHandler := handler.NewTagHandler(tagService)
+
+	r.Route("/api/tags", func(r chi.Router) {
+		r.Get("/", tagHandler.GetAll)
+		r.Get("/{id}", tagHandler.GetByID)
+		r.Post("/", tagHandler.Create)
+		r.Put("/{id}", tagHandler.Rename)
+		r.Delete("/{id}", tagHandler.Delete)
+		r.Post("/{id}/merge", tagHandler.Merge)
+	})
+}
+
+func setupCategoryHttpHandler(r chi.Router, categoryService *service.CategoryService) {
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
+	r.Route("/api/categories", func(r chi.Router) {
+		r.Get("/", categoryHandler.GetAll)
+		r.Post("/", categoryHandler.Create)
+		r.Put("/order", categoryHandler.Reorder)
+		r.Get("/{id}", categoryHandler.GetByID)
+		r.Put("/{id}", categoryHandler.Rename)
+		r.Delete("/{id}", categoryHandler.Delete)
+		r.Put("/{id}/feeds", categoryHandler.SetFeeds)
+		r.Get("/{id}/articles", categoryHandler.GetArticles)
+	})
+}
+
+func setupTimelineHttpHandler(r chi.Router, articleService *service.ArticleService) {
+	articleHandler := handler.NewArticleHandler(articleService)
+
+	r.Get("/api/timeline", articleHandler.GetTimeline)
+}
+
+func setupRuleHttpHandler(r chi.Router, ruleService *service.RuleService) {
+	ruleHandler := handler.NewRuleHandler(ruleService)
+
+	r.Route("/api/rules", func(r chi.Router) {
+		r.Get("/", ruleHandler.GetAll)
+		r.Post("/", ruleHandler.Create)
+		r.Post("/dry-run", ruleHandler.DryRun)
+		r.Get("/{id}", ruleHandler.GetByID)
+		r.Put("/{id}", ruleHandler.Update)
+		r.Delete("/{id}", ruleHandler.Delete)
+	})
+}
+
+func setupHighlightHttpHandler(r chi.Router, highlightService *service.HighlightService) {
+	highlightHandler := handler.NewHighlightHandler(highlightService)
+
+	r.Get("/api/highlights/export", highlightHandler.ExportMarkdown)
+}
+
+func setupCollectionHttpHandler(r chi.Router, collectionService *service.CollectionService) {
+	collectionHandler := handler.NewCollectionHandler(collectionService)
+
+	r.Route("/api/collections", func(r chi.Router) {
+		r.Get("/", collectionHandler.GetAll)
+		r.Post("/", collectionHandler.Create)
+		r.Get("/{id}", collectionHandler.GetByID)
+		r.Put("/{id}", collectionHandler.Update)
+		r.Delete("/{id}", collectionHandler.Delete)
+		r.Get("/{id}/articles", collectionHandler.GetArticles)
+		r.Put("/{id}/articles/{articleId}", collectionHandler.AddArticle)
+		r.Delete("/{id}/articles/{articleId}", collectionHandler.RemoveArticle)
+		r.Post("/{id}/articles/{articleId}/move", collectionHandler.MoveArticle)
+	})
+}
+
+func setupRetentionHttpHandler(r chi.Router, retentionService *service.RetentionService) {
+	retentionHandler := handler.NewRetentionHandler(retentionService)
+
+	r.Route("/api/retention", func(r chi.Router) {
+		r.Get("/policies", retentionHandler.GetPolicies)
+		r.Put("/policies/global", retentionHandler.UpdateGlobalPolicy)
+		r.Put("/policies/feeds/{feedId}", retentionHandler.UpdateFeedPolicy)
+		r.Delete("/policies/feeds/{feedId}", retentionHandler.DeleteFeedPolicy)
+		r.Get("/dry-run", retentionHandler.DryRun)
+		r.Get("/runs", retentionHandler.GetRuns)
+	})
+}
+
+func setupTrashHttpHandler(r chi.Router, trashService *service.TrashService) {
+	trashHandler := handler.NewTrashHandler(trashService)
+
+	r.Route("/api/trash", func(r chi.Router) {
+		r.Get("/", trashHandler.Get)
+		r.Post("/feeds/{id}/restore", trashHandler.RestoreFeed)
+		r.Post("/articles/{id}/restore", trashHandler.RestoreArticle)
+	})
+}
+
+func setupExportHttpHandler(r chi.Router, articleService *service.ArticleService, digestService *service.DigestService) {
+	exportHandler := handler.NewExportHandler(articleService, digestService)
+
+	r.Route("/api/export", func(r chi.Router) {
+		r.Get("/articles", exportHandler.ExportArticles)
+		r.Get("/epub", exportHandler.ExportEpub)
+	})
+}
+
+func setupShareHttpHandler(r chi.Router, shareService *service.ShareService) {
+	shareHandler := handler.NewShareHandler(shareService)
+
+	r.Route("/api/shares", func(r chi.Router) {
+		r.Get("/", shareHandler.GetActive)
+		r.Post("/", shareHandler.Create)
+		r.Delete("/{id}", shareHandler.Revoke)
+	})
+	// the public pages of shares are outside of the API
+	r.Get("/s/{token}", shareHandler.View)
+}
+
+func setupOPMLHttpHandler(r chi.Router, opmlService *service.OPMLService) {
+	opmlHandler := handler.NewOPMLHandler(opmlService)
+
+	r.Route("/api/opml", func(r chi.Router) {
+		r.Post("/import", opmlHandler.Import)
+		r.Get("/export", opmlHandler.Export)
+	})
+}
+
+func setupStatsHttpHandler(r chi.Router, statsService *service.StatsService) {
+	statsHandler := handler.NewStatsHandler(statsService)
+
+	r.Route("/api/stats", func(r chi.Router) {
+		r.Get("/overview", statsHandler.GetOverview)
+		r.Get("/feeds/{id}", statsHandler.GetFeed)
 	})
 }
 
@@ -164,16 +377,89 @@ func processRssFeeds(feedService *service.FeedService) {
 	fmt.Println("Finished scheduled RSS feed processing cycle")
 }
 
-func startDeleteOldArticlesJob(articleService *service.ArticleService) {
+func startCleanupJob(retentionService *service.RetentionService, trashService *service.TrashService, storyService *service.StoryService) {
 	interval := 24 * time.Hour // Default to 24 hours
 	ticker := time.NewTicker(interval)
 
 	for range ticker.C {
-		err := articleService.DeleteOneWeekOldArticles(context.Background())
+		run, err := retentionService.RunCleanup(context.Background())
+		if err != nil {
+			log.Printf("Error trashing expired articles: %v\n", err)
+		} else {
+			log.Printf("Moved %d expired articles to the trash\n", run.DeletedCount)
+		}
+
+		purged, err := trashService.Purge(context.Background())
 		if err != nil {
-			log.Printf("Error deleting old articles: %v\n", err)
+			log.Printf("Error purging trash: %v\n", err)
 		} else {
-			log.Println("Deleted articles older than one week")
+			log.Printf("Purged %d feeds and %d articles from the trash\n", purged.Feeds, purged.Articles)
 		}
+
+		if err := storyService.CleanupClusters(context.Background()); err != nil {
+			log.Printf("Error cleaning up story clusters: %v\n", err)
+		}
+	}
+}
+
+// startFeedValidationJob validates all feeds once a week, failed validations show up in the feed health
+func startFeedValidationJob(feedService *service.FeedService) {
+	ticker := time.NewTicker(7 * 24 * time.Hour)
+
+	for range ticker.C {
+		result, err := feedService.Revalidate(context.Background())
+		if err != nil {
+			log.Printf("Error validating feeds: %v\n", err)
+		} else {
+			log.Printf("Validated %d feeds, %d failed\n", result.Validated, result.Failed)
+		}
+	}
+}
+
+// startDigestJob writes the EPUB digest of the unread articles of the last 24 hours to the directory once a day at
+// the given time of day, so consecutive digests cover the articles in between
+func startDigestJob(digestService *service.DigestService, dir string, at time.Time) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		time.Sleep(time.Until(next))
+
+		path, err := digestService.WriteDaily(context.Background(), dir, time.Now())
+		if errors.Is(err, service.ErrEmptyDigest) {
+			log.Println("No unread articles since the last digest, skipping EPUB digest")
+		} else if err != nil {
+			log.Printf("Error writing EPUB digest: %v\n", err)
+		} else {
+			log.Printf("Wrote EPUB digest to %s\n", path)
+		}
+	}
+}
+
+// getDigestTime returns the time of day of the daily EPUB digest from EPUB_DIGEST_TIME
+func getDigestTime() time.Time {
+	digestTime := os.Getenv("EPUB_DIGEST_TIME")
+	if digestTime == "" {
+		digestTime = "06:00" // Default to 6 in the morning
+	}
+	at, err := time.Parse("15:04", digestTime)
+	if err != nil {
+		log.Fatalf("Invalid EPUB_DIGEST_TIME: %s", digestTime)
+	}
+	return at
+}
+
+// getTrashPurgeAfter returns the grace period of trashed feeds and articles from TRASH_PURGE_DAYS
+func getTrashPurgeAfter() time.Duration {
+	purgeDays := os.Getenv("TRASH_PURGE_DAYS")
+	if purgeDays == "" {
+		purgeDays = "30" // Default to 30 days
+	}
+	days, err := strconv.Atoi(purgeDays)
+	if err != nil || days < 0 {
+		log.Fatalf("Invalid TRASH_PURGE_DAYS: %s", purgeDays)
 	}
+	return time.Duration(days) * 24 * time.Hour
 }
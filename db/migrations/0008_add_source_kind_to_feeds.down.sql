@@ -0,0 +1,3 @@
+-- Remove source kind and configuration from feeds
+ALTER TABLE feeds DROP COLUMN IF EXISTS source_config;
+ALTER TABLE feeds DROP COLUMN IF EXISTS source_kind;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Add source kind and per-kind configuration to feeds
+ALTER TABLE feeds ADD COLUMN source_kind VARCHAR(50) NOT NULL DEFAULT 'rss';
+ALTER TABLE feeds ADD COLUMN source_config JSONB NOT NULL DEFAULT '{}';
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Remove language from articles
+DROP INDEX IF EXISTS idx_articles_language;
+ALTER TABLE articles DROP COLUMN IF EXISTS language;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Add detected language (ISO 639-1) to articles, empty if unknown
+ALTER TABLE articles ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Index for filtering articles by language
+CREATE INDEX idx_articles_language ON articles(language);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Remove content and reading statistics from articles
+DROP INDEX IF EXISTS idx_articles_reading_time;
+ALTER TABLE articles DROP COLUMN IF EXISTS reading_time;
+ALTER TABLE articles DROP COLUMN IF EXISTS word_count;
+ALTER TABLE articles DROP COLUMN IF EXISTS content;
\ No newline at end of file
@@ -0,0 +1,13 @@
+-- Store full content and reading statistics on articles
+ALTER TABLE articles ADD COLUMN content TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN word_count INT NOT NULL DEFAULT 0;
+ALTER TABLE articles ADD COLUMN reading_time INT NOT NULL DEFAULT 0;
+
+-- Best-effort backfill from the description (HTML tags removed), 200 words per minute
+UPDATE articles
+SET word_count = COALESCE(array_length(regexp_split_to_array(NULLIF(TRIM(regexp_replace(description, '<[^>]*>', ' ', 'g')), ''), '\s+'), 1), 0);
+UPDATE articles
+SET reading_time = CEIL(word_count / 200.0);
+
+-- Index for filtering and sorting by reading time
+CREATE INDEX idx_articles_reading_time ON articles(reading_time);
\ No newline at end of file
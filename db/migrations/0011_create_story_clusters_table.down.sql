@@ -0,0 +1,8 @@
+-- Remove story clusters
+DROP INDEX IF EXISTS idx_story_clusters_updated_at;
+DROP INDEX IF EXISTS idx_articles_published_at;
+DROP INDEX IF EXISTS idx_articles_story_cluster_id;
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS fk_articles_story_cluster_id;
+ALTER TABLE articles DROP COLUMN IF EXISTS story_cluster_id;
+ALTER TABLE articles DROP COLUMN IF EXISTS simhash;
+DROP TABLE IF EXISTS story_clusters;
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- Create story clusters grouping near-duplicate articles of different feeds
+CREATE TABLE story_clusters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    representative_article_id UUID REFERENCES articles(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- SimHash fingerprint over title and description, 0 if not computed
+ALTER TABLE articles ADD COLUMN simhash BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE articles ADD COLUMN story_cluster_id UUID;
+
+ALTER TABLE articles ADD CONSTRAINT fk_articles_story_cluster_id
+    FOREIGN KEY (story_cluster_id) REFERENCES story_clusters(id) ON DELETE SET NULL;
+
+-- Index for loading the members of a cluster
+CREATE INDEX idx_articles_story_cluster_id ON articles(story_cluster_id);
+
+-- Index for loading cluster candidates within a time window
+CREATE INDEX idx_articles_published_at ON articles(published_at);
+
+-- Index for listing the latest stories
+CREATE INDEX idx_story_clusters_updated_at ON story_clusters(updated_at);
\ No newline at end of file
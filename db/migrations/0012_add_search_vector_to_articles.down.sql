@@ -0,0 +1,4 @@
+-- Remove full-text search from articles
+DROP INDEX IF EXISTS idx_articles_search_vector;
+ALTER TABLE articles DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS article_ts_config(TEXT);
\ No newline at end of file
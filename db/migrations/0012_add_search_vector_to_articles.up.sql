@@ -0,0 +1,22 @@
+-- Text search configuration for an ISO 639-1 article language, 'simple' for unknown languages
+CREATE OR REPLACE FUNCTION article_ts_config(lang TEXT) RETURNS regconfig AS $$
+    SELECT CASE lang
+        WHEN 'de' THEN 'german'::regconfig
+        WHEN 'en' THEN 'english'::regconfig
+        WHEN 'fr' THEN 'french'::regconfig
+        WHEN 'es' THEN 'spanish'::regconfig
+        WHEN 'it' THEN 'italian'::regconfig
+        WHEN 'nl' THEN 'dutch'::regconfig
+        ELSE 'simple'::regconfig
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Weighted full-text search vector over title, description and content
+ALTER TABLE articles ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector(article_ts_config(language), title), 'A') ||
+    setweight(to_tsvector(article_ts_config(language), description), 'B') ||
+    setweight(to_tsvector(article_ts_config(language), content), 'C')
+) STORED;
+
+-- Index for full-text search
+CREATE INDEX idx_articles_search_vector ON articles USING GIN (search_vector);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Remove the keyset pagination indexes
+DROP INDEX IF EXISTS idx_articles_saved_published_at_id;
+DROP INDEX IF EXISTS idx_articles_last_read_at_id;
+DROP INDEX IF EXISTS idx_articles_feed_id_published_at_id;
+DROP INDEX IF EXISTS idx_articles_published_at_id;
+
+CREATE INDEX idx_articles_published_at ON articles(published_at);
\ No newline at end of file
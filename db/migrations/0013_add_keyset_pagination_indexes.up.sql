@@ -0,0 +1,12 @@
+-- Indexes for keyset pagination of the article lists, the id breaks ties between equal sort keys
+DROP INDEX IF EXISTS idx_articles_published_at;
+CREATE INDEX idx_articles_published_at_id ON articles(published_at DESC, id DESC);
+
+-- Index for paginating the articles of a feed
+CREATE INDEX idx_articles_feed_id_published_at_id ON articles(feed_id, published_at DESC, id DESC);
+
+-- Index for paginating the history
+CREATE INDEX idx_articles_last_read_at_id ON articles(last_read_at DESC, id DESC);
+
+-- Index for paginating saved articles
+CREATE INDEX idx_articles_saved_published_at_id ON articles(published_at DESC, id DESC) WHERE save = true;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Restore the zero time for unread articles
+ALTER TABLE articles DROP COLUMN IF EXISTS opened_at;
+UPDATE articles SET last_read_at = '0001-01-01 00:00:00+00' WHERE last_read_at IS NULL;
\ No newline at end of file
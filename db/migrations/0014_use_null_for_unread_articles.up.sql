@@ -0,0 +1,8 @@
+-- Unread articles have no last_read_at instead of the zero time
+UPDATE articles SET last_read_at = NULL WHERE last_read_at < '0002-01-01 00:00:00+00';
+
+-- Opening an article is tracked separately from marking it as read
+ALTER TABLE articles ADD COLUMN opened_at TIMESTAMP WITH TIME ZONE;
+
+-- Until now articles were marked as read when they were opened
+UPDATE articles SET opened_at = last_read_at WHERE last_read_at IS NOT NULL;
\ No newline at end of file
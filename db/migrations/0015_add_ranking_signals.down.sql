@@ -0,0 +1,3 @@
+-- Remove the ranking signals
+ALTER TABLE feeds DROP COLUMN IF EXISTS weight;
+ALTER TABLE articles DROP COLUMN IF EXISTS dwell_seconds;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Seconds the article was open in the client, reported by the client
+ALTER TABLE articles ADD COLUMN dwell_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- Explicit weight of a feed in the For You ranking, 0 hides its articles
+ALTER TABLE feeds ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 1 CHECK (weight >= 0);
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Remove user-managed tags
+DROP TABLE IF EXISTS article_tags;
+DROP TABLE IF EXISTS tags;
\ No newline at end of file
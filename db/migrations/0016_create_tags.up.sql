@@ -0,0 +1,19 @@
+-- Create user-managed tags for organizing articles
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Tag names are unique regardless of case
+CREATE UNIQUE INDEX idx_tags_name ON tags(LOWER(name));
+
+CREATE TABLE article_tags (
+    article_id UUID NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_id, tag_id)
+);
+
+-- Index for listing and counting the articles of a tag
+CREATE INDEX idx_article_tags_tag_id ON article_tags(tag_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Remove categories
+DROP INDEX IF EXISTS idx_feeds_category_id_position;
+ALTER TABLE feeds DROP COLUMN IF EXISTS position;
+ALTER TABLE feeds DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS categories;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- Create categories grouping feeds, ordered by the user
+CREATE TABLE categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Category names are unique regardless of case
+CREATE UNIQUE INDEX idx_categories_name ON categories(LOWER(name));
+
+-- Feeds without category are uncategorized, position orders feeds within their category
+ALTER TABLE feeds ADD COLUMN category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+ALTER TABLE feeds ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- Index for listing the feeds of a category in order
+CREATE INDEX idx_feeds_category_id_position ON feeds(category_id, position);
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Remove the feed icons
+ALTER TABLE feeds DROP COLUMN IF EXISTS icon_url;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Icon of the feed taken from the feed image or the favicon of its site, NULL if unknown
+ALTER TABLE feeds ADD COLUMN icon_url TEXT;
\ No newline at end of file
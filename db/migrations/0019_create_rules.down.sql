@@ -0,0 +1,6 @@
+-- Remove the rules and the article fields they use
+DROP TABLE IF EXISTS rules;
+DROP INDEX IF EXISTS idx_articles_priority_published_at_id;
+ALTER TABLE articles DROP COLUMN IF EXISTS priority;
+ALTER TABLE articles DROP COLUMN IF EXISTS categories;
+ALTER TABLE articles DROP COLUMN IF EXISTS author;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- Author and categories of the source item, matched by rules
+ALTER TABLE articles ADD COLUMN author TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+-- Priority set by rules, higher is more important
+ALTER TABLE articles ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+-- Index for the keyset pagination of the priority sort
+CREATE INDEX idx_articles_priority_published_at_id ON articles(priority DESC, published_at DESC, id DESC);
+
+-- Filter rules applied to new articles during ingestion, rules without feed apply to all feeds
+CREATE TABLE rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    conditions JSONB NOT NULL DEFAULT '[]',
+    actions JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    match_count INTEGER NOT NULL DEFAULT 0,
+    last_matched_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Index for loading the rules of a feed
+CREATE INDEX idx_rules_feed_id ON rules(feed_id);
\ No newline at end of file
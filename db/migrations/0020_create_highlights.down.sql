@@ -0,0 +1,3 @@
+-- Remove highlights and article notes
+DROP TABLE IF EXISTS highlights;
+ALTER TABLE articles DROP COLUMN IF EXISTS note;
\ No newline at end of file
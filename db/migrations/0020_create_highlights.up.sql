@@ -0,0 +1,17 @@
+-- Free-form note of an article
+ALTER TABLE articles ADD COLUMN note TEXT NOT NULL DEFAULT '';
+
+-- Highlighted quotes of articles with their position in the text and an optional note
+CREATE TABLE highlights (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    article_id UUID NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    quote TEXT NOT NULL,
+    anchor JSONB NOT NULL DEFAULT '{}',
+    color TEXT NOT NULL DEFAULT 'yellow',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Index for listing the highlights of an article
+CREATE INDEX idx_highlights_article_id ON highlights(article_id);
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- Remove collections, saved articles keep their save flag
+DROP TRIGGER IF EXISTS trg_articles_sync_default_collection ON articles;
+DROP FUNCTION IF EXISTS sync_default_collection();
+DROP TABLE IF EXISTS collection_articles;
+DROP TABLE IF EXISTS collections;
\ No newline at end of file
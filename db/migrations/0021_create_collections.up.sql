@@ -0,0 +1,55 @@
+-- Create named collections of articles, the default collection holds the saved articles
+CREATE TABLE collections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Collection names are unique regardless of case and there is only one default collection
+CREATE UNIQUE INDEX idx_collections_name ON collections(LOWER(name));
+CREATE UNIQUE INDEX idx_collections_default ON collections(is_default) WHERE is_default;
+
+CREATE TABLE collection_articles (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    article_id UUID NOT NULL REFERENCES articles(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (collection_id, article_id)
+);
+
+-- Indexes for listing a collection in order and the collections of an article
+CREATE INDEX idx_collection_articles_position ON collection_articles(collection_id, position, article_id);
+CREATE INDEX idx_collection_articles_article_id ON collection_articles(article_id);
+
+-- The default collection contains the saved articles, newest first
+INSERT INTO collections (name, description, is_default) VALUES ('Saved', 'Articles saved with the save button', true);
+
+INSERT INTO collection_articles (collection_id, article_id, position)
+SELECT c.id, a.id, ROW_NUMBER() OVER (ORDER BY a.published_at DESC, a.id DESC) - 1
+FROM articles a, collections c
+WHERE a.save AND c.is_default;
+
+-- Keep the default collection in sync with articles.save, so the save endpoints, bulk operations and rules keep working
+CREATE FUNCTION sync_default_collection() RETURNS trigger AS $$
+BEGIN
+    IF NEW.save AND (TG_OP = 'INSERT' OR NOT OLD.save) THEN
+        INSERT INTO collection_articles (collection_id, article_id, position)
+        SELECT c.id, NEW.id, COALESCE((SELECT MAX(ca.position) + 1 FROM collection_articles ca WHERE ca.collection_id = c.id), 0)
+        FROM collections c
+        WHERE c.is_default
+        ON CONFLICT DO NOTHING;
+    ELSIF TG_OP = 'UPDATE' AND OLD.save AND NOT NEW.save THEN
+        DELETE FROM collection_articles ca
+        USING collections c
+        WHERE ca.collection_id = c.id AND c.is_default AND ca.article_id = NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_articles_sync_default_collection
+AFTER INSERT OR UPDATE OF save ON articles
+FOR EACH ROW EXECUTE FUNCTION sync_default_collection();
\ No newline at end of file
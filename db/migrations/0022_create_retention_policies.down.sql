@@ -0,0 +1,3 @@
+-- Drop retention policies and runs
+DROP TABLE IF EXISTS retention_runs;
+DROP TABLE IF EXISTS retention_policies;
\ No newline at end of file
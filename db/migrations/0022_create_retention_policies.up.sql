@@ -0,0 +1,28 @@
+-- Retention policies of the cleanup, the policy without feed is the global default
+CREATE TABLE retention_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    feed_id UUID UNIQUE REFERENCES feeds(id) ON DELETE CASCADE,
+    max_age_days INTEGER,
+    keep_latest INTEGER,
+    unread_max_age_days INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- There is only one global policy
+CREATE UNIQUE INDEX idx_retention_policies_global ON retention_policies((feed_id IS NULL)) WHERE feed_id IS NULL;
+
+-- The global default keeps the previous behaviour of deleting articles older than a week
+INSERT INTO retention_policies (max_age_days) VALUES (7);
+
+-- Every cleanup run with the number of deleted articles per feed
+CREATE TABLE retention_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    deleted_count INTEGER NOT NULL DEFAULT 0,
+    feeds JSONB NOT NULL DEFAULT '[]',
+    error TEXT
+);
+
+CREATE INDEX idx_retention_runs_started_at ON retention_runs(started_at DESC);
\ No newline at end of file
@@ -0,0 +1,7 @@
+-- Remove the trash, trashed feeds and articles are deleted
+DROP INDEX IF EXISTS idx_articles_deleted_at_id;
+DROP INDEX IF EXISTS idx_feeds_deleted_at;
+DELETE FROM articles WHERE deleted_at IS NOT NULL;
+DELETE FROM feeds WHERE deleted_at IS NOT NULL;
+ALTER TABLE articles DROP COLUMN deleted_at;
+ALTER TABLE feeds DROP COLUMN deleted_at;
\ No newline at end of file
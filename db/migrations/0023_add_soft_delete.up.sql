@@ -0,0 +1,7 @@
+-- Deleted feeds and articles are moved to the trash and purged after a grace period
+ALTER TABLE feeds ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE articles ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Indexes for listing and purging the trash
+CREATE INDEX idx_feeds_deleted_at ON feeds(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_articles_deleted_at_id ON articles(deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
\ No newline at end of file
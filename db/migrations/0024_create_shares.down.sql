@@ -0,0 +1,2 @@
+-- Remove share links
+DROP TABLE IF EXISTS shares;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Create public read-only share links for a single article or a collection
+CREATE TABLE shares (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token TEXT NOT NULL UNIQUE,
+    article_id UUID REFERENCES articles(id) ON DELETE CASCADE,
+    collection_id UUID REFERENCES collections(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- expires_at is NULL for shares that never expire
+    expires_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT shares_single_target CHECK ((article_id IS NULL) != (collection_id IS NULL))
+);
+
+CREATE INDEX idx_shares_article_id ON shares(article_id);
+CREATE INDEX idx_shares_collection_id ON shares(collection_id);
\ No newline at end of file
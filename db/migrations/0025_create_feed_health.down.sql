@@ -0,0 +1,2 @@
+-- Remove feed health tracking
+DROP TABLE IF EXISTS feed_health;
\ No newline at end of file
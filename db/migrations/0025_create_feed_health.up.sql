@@ -0,0 +1,11 @@
+-- Track fetch and validation results of feeds for the health dashboard
+CREATE TABLE feed_health (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    last_success_at TIMESTAMP WITH TIME ZONE,
+    last_failure_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_validated_at TIMESTAMP WITH TIME ZONE,
+    -- validation_error is empty if the last validation succeeded
+    validation_error TEXT NOT NULL DEFAULT ''
+);
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Remove the statistics columns and restore the feed index
+CREATE INDEX IF NOT EXISTS idx_articles_feed_id ON articles(feed_id);
+
+DROP INDEX IF EXISTS idx_articles_feed_id_saved_at;
+DROP INDEX IF EXISTS idx_articles_saved_at;
+DROP INDEX IF EXISTS idx_articles_feed_id_last_read_at;
+DROP INDEX IF EXISTS idx_articles_feed_id_ingested_at;
+DROP INDEX IF EXISTS idx_articles_ingested_at;
+
+DROP TRIGGER IF EXISTS trg_articles_set_saved_at ON articles;
+DROP FUNCTION IF EXISTS set_article_saved_at();
+
+ALTER TABLE articles DROP COLUMN IF EXISTS saved_at;
+ALTER TABLE articles DROP COLUMN IF EXISTS ingested_at;
\ No newline at end of file
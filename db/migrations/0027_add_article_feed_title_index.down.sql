@@ -0,0 +1,2 @@
+-- Remove the index to find articles fetched before
+DROP INDEX IF EXISTS idx_articles_feed_id_title;
\ No newline at end of file
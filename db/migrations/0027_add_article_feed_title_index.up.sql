@@ -0,0 +1,2 @@
+-- Index to find articles fetched before by feed and title when their content changed
+CREATE INDEX idx_articles_feed_id_title ON articles(feed_id, title);
\ No newline at end of file
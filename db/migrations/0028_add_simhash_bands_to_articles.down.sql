@@ -0,0 +1,3 @@
+-- Remove the SimHash bands
+DROP INDEX IF EXISTS idx_articles_simhash_bands;
+ALTER TABLE articles DROP COLUMN IF EXISTS simhash_bands;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- SimHash bands to find cluster candidates by index instead of comparing every article of the window.
+-- Each element is the position of a 16 bit band times 65536 plus its value, computed like simHashBands.
+ALTER TABLE articles ADD COLUMN simhash_bands INT[] GENERATED ALWAYS AS (ARRAY[
+    (simhash & 65535)::int,
+    65536 + ((simhash >> 16) & 65535)::int,
+    131072 + ((simhash >> 32) & 65535)::int,
+    196608 + ((simhash >> 48) & 65535)::int
+]) STORED;
+
+CREATE INDEX idx_articles_simhash_bands ON articles USING GIN (simhash_bands);
\ No newline at end of file
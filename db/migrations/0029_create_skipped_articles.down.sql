@@ -0,0 +1,2 @@
+-- Remove the skipped articles
+DROP TABLE IF EXISTS skipped_articles;
\ No newline at end of file
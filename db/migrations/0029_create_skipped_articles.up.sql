@@ -0,0 +1,6 @@
+-- Content hashes of articles skipped by rules, so they are recognized as duplicates on the next fetch
+CREATE TABLE skipped_articles (
+    content_hash VARCHAR(64) PRIMARY KEY,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    skipped_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
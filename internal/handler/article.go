@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
 	"github.com/lucasg04/fyrss-server/internal/service"
 )
 
@@ -20,9 +23,15 @@ func NewArticleHandler(svc *service.ArticleService) *ArticleHandler {
 }
 
 func (h *ArticleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	articles, err := h.svc.GetAll(r.Context())
+	filter, err := getArticleFilter(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetAll(r.Context(), filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
 		return
 	}
 
@@ -47,35 +56,68 @@ func (h *ArticleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ArticleHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
-	from, to, err := getPaginationParams(r)
+	page, legacy, err := getPageRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	articles, err := h.svc.GetHistoryPaginated(r.Context(), from, to)
+	filter, err := getArticleFilter(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	handlerutil.JsonResponse(w, articles)
+	articles, err := h.svc.GetHistoryPaginated(r.Context(), page, filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	writeArticlePage(w, articles, legacy)
 }
 
 func (h *ArticleHandler) GetSaved(w http.ResponseWriter, r *http.Request) {
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetSavedPaginated(r.Context(), page, filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	writeArticlePage(w, articles, legacy)
+}
+
+func (h *ArticleHandler) Search(w http.ResponseWriter, r *http.Request) {
 	from, to, err := getPaginationParams(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	articles, err := h.svc.GetSavedPaginated(r.Context(), from, to)
+	results, err := h.svc.Search(r.Context(), r.URL.Query().Get("q"), from, to, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), articleErrorStatus(err))
 		return
 	}
 
-	handlerutil.JsonResponse(w, articles)
+	handlerutil.JsonResponse(w, results)
 }
 
 func (h *ArticleHandler) UpdateSavedByID(w http.ResponseWriter, r *http.Request) {
@@ -94,7 +136,7 @@ func (h *ArticleHandler) UpdateSavedByID(w http.ResponseWriter, r *http.Request)
 
 	err = h.svc.UpdateSavedByID(r.Context(), id, saved)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), articleErrorStatus(err))
 		return
 	}
 
@@ -109,9 +151,56 @@ func (h *ArticleHandler) UpdateReadByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = h.svc.UpdateReadByID(r.Context(), id)
+	// read defaults to true for clients which only mark articles as read
+	read, err := getOptionalBoolParam(r, "read")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.UpdateReadByID(r.Context(), id, read == nil || *read)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ArticleHandler) UpdateOpenedByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.UpdateOpenedByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *ArticleHandler) AddDwellByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	seconds, err := strconv.Atoi(r.URL.Query().Get("seconds"))
+	if err != nil {
+		http.Error(w, "Invalid seconds parameter", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.AddDwellByID(r.Context(), id, time.Duration(seconds)*time.Second)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), articleErrorStatus(err))
 		return
 	}
 
@@ -125,15 +214,47 @@ func (h *ArticleHandler) GetPaginatedByFeedID(w http.ResponseWriter, r *http.Req
 		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
 		return
 	}
-	from, to, err := getPaginationParams(r)
+	page, legacy, err := getPageRequest(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	articles, err := h.svc.GetPaginatedByFeedID(r.Context(), feedID, from, to)
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetPaginatedByFeedID(r.Context(), feedID, page, filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	writeArticlePage(w, articles, legacy)
+}
+
+func (h *ArticleHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	page, legacy, err := getPageRequest(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if legacy {
+		http.Error(w, "from and to are not supported, use cursor and limit", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetTimeline(r.Context(), page, filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
 		return
 	}
 
@@ -156,3 +277,111 @@ func getPaginationParams(r *http.Request) (int, int, error) {
 
 	return from, to, nil
 }
+
+func (h *ArticleHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var req model.BulkArticleRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.svc.Bulk(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, result)
+}
+
+func (h *ArticleHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	h.markRead(w, r, nil)
+}
+
+func (h *ArticleHandler) MarkFeedRead(w http.ResponseWriter, r *http.Request) {
+	feedIDStr := chi.URLParam(r, "feedId")
+	feedID, err := uuid.Parse(feedIDStr)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+	h.markRead(w, r, &feedID)
+}
+
+// markRead marks the articles published before the optional before parameter, or now, as read
+func (h *ArticleHandler) markRead(w http.ResponseWriter, r *http.Request, feedID *uuid.UUID) {
+	before, err := getOptionalTimeParam(r, "before")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if before == nil {
+		now := time.Now()
+		before = &now
+	}
+
+	result, err := h.svc.MarkRead(r.Context(), feedID, *before)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, result)
+}
+
+// maxPageSize is the largest limit of cursor pagination
+const maxPageSize = 100
+
+// getPageRequest parses the cursor, limit and count parameters of paginated article lists.
+// legacy is true for the deprecated from and to parameters, which are translated to an offset.
+func getPageRequest(r *http.Request) (page model.PageRequest, legacy bool, err error) {
+	query := r.URL.Query()
+	if query.Has("from") || query.Has("to") {
+		if query.Has("cursor") || query.Has("limit") {
+			return page, false, fmt.Errorf("from and to cannot be combined with cursor or limit")
+		}
+		from, to, err := getPaginationParams(r)
+		if err != nil {
+			return page, false, err
+		}
+		return model.PageRequest{Offset: from, Limit: to - from}, true, nil
+	}
+
+	page.Cursor = query.Get("cursor")
+	if limitStr := query.Get("limit"); limitStr != "" {
+		page.Limit, err = strconv.Atoi(limitStr)
+		if err != nil || page.Limit < 1 || page.Limit > maxPageSize {
+			return page, false, fmt.Errorf("invalid limit parameter: %s, must be between 1 and %d", limitStr, maxPageSize)
+		}
+	}
+	count, err := getOptionalBoolParam(r, "count")
+	if err != nil {
+		return page, false, err
+	}
+	page.IncludeTotal = count != nil && *count
+	return page, false, nil
+}
+
+// writeArticlePage writes the page, or only its articles for requests with the deprecated from and to parameters
+func writeArticlePage(w http.ResponseWriter, page *model.ArticlePage, legacy bool) {
+	if legacy {
+		w.Header().Set("Deprecation", "true")
+		handlerutil.JsonResponse(w, page.Articles)
+		return
+	}
+	handlerutil.JsonResponse(w, page)
+}
+
+// articleErrorStatus maps errors of the article service to HTTP status codes
+func articleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidArticleFilter), errors.Is(err, service.ErrEmptySearchQuery),
+		errors.Is(err, service.ErrInvalidPagination), errors.Is(err, service.ErrInvalidBulkRequest),
+		errors.Is(err, service.ErrInvalidDwell):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrArticleNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+// getArticleFilter parses the optional filter and sort parameters of article list endpoints.
+// Contradicting combinations are rejected by the article service.
+func getArticleFilter(r *http.Request) (model.ArticleFilter, error) {
+	var filter model.ArticleFilter
+	query := r.URL.Query()
+
+	for _, feedIDStr := range query["feedId"] {
+		feedID, err := uuid.Parse(feedIDStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid feedId parameter: %s", feedIDStr)
+		}
+		filter.FeedIDs = append(filter.FeedIDs, feedID)
+	}
+
+	for _, tagIDStr := range query["tagId"] {
+		tagID, err := uuid.Parse(tagIDStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid tagId parameter: %s", tagIDStr)
+		}
+		filter.TagIDs = append(filter.TagIDs, tagID)
+	}
+
+	if langStr := query.Get("lang"); langStr != "" {
+		filter.Language = service.NormalizeLanguage(langStr)
+		if filter.Language == "" {
+			return filter, fmt.Errorf("invalid lang parameter: %s", langStr)
+		}
+	}
+
+	filter.SourceType = strings.ToLower(strings.TrimSpace(query.Get("sourceType")))
+	filter.TitleContains = strings.TrimSpace(query.Get("title"))
+
+	var err error
+	if filter.Saved, err = getOptionalBoolParam(r, "saved"); err != nil {
+		return filter, err
+	}
+	if filter.Read, err = getOptionalBoolParam(r, "read"); err != nil {
+		return filter, err
+	}
+
+	if filter.PublishedFrom, err = getOptionalTimeParam(r, "publishedFrom"); err != nil {
+		return filter, err
+	}
+	if filter.PublishedTo, err = getOptionalTimeParam(r, "publishedTo"); err != nil {
+		return filter, err
+	}
+	if filter.ReadFrom, err = getOptionalTimeParam(r, "readFrom"); err != nil {
+		return filter, err
+	}
+	if filter.ReadTo, err = getOptionalTimeParam(r, "readTo"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinReadingTime, err = getOptionalNonNegativeIntParam(r, "minReadingTime"); err != nil {
+		return filter, err
+	}
+	if filter.MaxReadingTime, err = getOptionalNonNegativeIntParam(r, "maxReadingTime"); err != nil {
+		return filter, err
+	}
+
+	collapseClusters, err := getOptionalBoolParam(r, "collapseClusters")
+	if err != nil {
+		return filter, err
+	}
+	filter.CollapseClusters = collapseClusters != nil && *collapseClusters
+
+	switch sort := model.ArticleSort(query.Get("sort")); sort {
+	case "", model.ArticleSortNewest, model.ArticleSortOldest, model.ArticleSortShortest, model.ArticleSortLongest, model.ArticleSortRecentlyRead,
+		model.ArticleSortPriority:
+		filter.Sort = sort
+	default:
+		return filter, fmt.Errorf("invalid sort parameter: %s", sort)
+	}
+
+	return filter, nil
+}
+
+func getOptionalBoolParam(r *http.Request, param string) (*bool, error) {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %s", param, valueStr)
+	}
+	return &value, nil
+}
+
+func getOptionalNonNegativeIntParam(r *http.Request, param string) (*int, error) {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		return nil, fmt.Errorf("invalid %s parameter: %s", param, valueStr)
+	}
+	return &value, nil
+}
+
+// getOptionalTimeParam parses an optional RFC 3339 timestamp or date (YYYY-MM-DD)
+func getOptionalTimeParam(r *http.Request, param string) (*time.Time, error) {
+	valueStr := r.URL.Query().Get(param)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		value, err = time.Parse(time.DateOnly, valueStr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s parameter: %s", param, valueStr)
+	}
+	return &value, nil
+}
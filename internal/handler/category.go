@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type CategoryHandler struct {
+	svc *service.CategoryService
+}
+
+func NewCategoryHandler(svc *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{svc: svc}
+}
+
+func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.svc.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, categories)
+}
+
+func (h *CategoryHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, category)
+}
+
+func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateCategoryRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, category)
+}
+
+func (h *CategoryHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateCategoryRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.svc.Rename(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, category)
+}
+
+func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Delete(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CategoryHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	var req model.ReorderCategoriesRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := h.svc.Reorder(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, categories)
+}
+
+func (h *CategoryHandler) SetFeeds(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.SetCategoryFeedsRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.svc.SetFeeds(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, category)
+}
+
+func (h *CategoryHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetArticles(r.Context(), id, page, filter)
+	if err != nil {
+		http.Error(w, err.Error(), categoryErrorStatus(err))
+		return
+	}
+
+	writeArticlePage(w, articles, legacy)
+}
+
+// categoryErrorStatus maps errors of the category service to HTTP status codes
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategoryName), errors.Is(err, service.ErrInvalidCategoryOrder):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDuplicateCategoryName):
+		return http.StatusConflict
+	default:
+		return articleErrorStatus(err)
+	}
+}
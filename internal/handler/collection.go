@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type CollectionHandler struct {
+	svc *service.CollectionService
+}
+
+func NewCollectionHandler(svc *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{svc: svc}
+}
+
+func (h *CollectionHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.svc.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, collections)
+}
+
+func (h *CollectionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, collection)
+}
+
+func (h *CollectionHandler) GetByArticleID(w http.ResponseWriter, r *http.Request) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	collections, err := h.svc.GetByArticleID(r.Context(), articleID)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, collections)
+}
+
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateCollectionRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, collection)
+}
+
+func (h *CollectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateCollectionRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.svc.Update(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, collection)
+}
+
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Delete(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CollectionHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return
+	}
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if legacy {
+		http.Error(w, "from and to are not supported, use cursor and limit", http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetArticles(r.Context(), id, page)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, articles)
+}
+
+func (h *CollectionHandler) AddArticle(w http.ResponseWriter, r *http.Request) {
+	id, articleID, err := getCollectionArticleParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	position, err := getOptionalNonNegativeIntParam(r, "position")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.AddArticle(r.Context(), id, articleID, position)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CollectionHandler) RemoveArticle(w http.ResponseWriter, r *http.Request) {
+	id, articleID, err := getCollectionArticleParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.RemoveArticle(r.Context(), id, articleID)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CollectionHandler) MoveArticle(w http.ResponseWriter, r *http.Request) {
+	id, articleID, err := getCollectionArticleParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req model.MoveCollectionArticleRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.MoveArticle(r.Context(), id, articleID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), collectionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getCollectionArticleParams(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid collection ID")
+	}
+	articleID, err := uuid.Parse(chi.URLParam(r, "articleId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid article ID")
+	}
+	return id, articleID, nil
+}
+
+// collectionErrorStatus maps errors of the collection service to HTTP status codes
+func collectionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollection), errors.Is(err, service.ErrInvalidCollectionPosition),
+		errors.Is(err, service.ErrInvalidPagination):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCollectionNotFound), errors.Is(err, service.ErrArticleNotFound),
+		errors.Is(err, service.ErrArticleNotInCollection):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDuplicateCollectionName), errors.Is(err, service.ErrDefaultCollection):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type ExportHandler struct {
+	articleSvc *service.ArticleService
+	digestSvc  *service.DigestService
+}
+
+func NewExportHandler(articleSvc *service.ArticleService, digestSvc *service.DigestService) *ExportHandler {
+	return &ExportHandler{articleSvc: articleSvc, digestSvc: digestSvc}
+}
+
+// ExportArticles streams the saved articles matching the filter as a file download, JSON by default
+func (h *ExportHandler) ExportArticles(w http.ResponseWriter, r *http.Request) {
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := model.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = model.ExportFormatJSON
+	}
+
+	export, err := h.articleSvc.NewExport(format, filter)
+	if err != nil {
+		http.Error(w, err.Error(), exportErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName(time.Now())))
+	if err := export.Write(r.Context(), w); err != nil {
+		// the status is already sent, the client gets a truncated file
+		log.Printf("Failed to export articles: %v\n", err)
+	}
+}
+
+// ExportEpub builds an EPUB digest of a collection, of the articles matching the filter,
+// or of the unread articles of the last 24 hours without parameters
+func (h *ExportHandler) ExportEpub(w http.ResponseWriter, r *http.Request) {
+	var selection model.DigestSelection
+	query := r.URL.Query()
+	if collectionIDStr := query.Get("collectionId"); collectionIDStr != "" {
+		if len(query) > 1 {
+			http.Error(w, "collectionId cannot be combined with other parameters", http.StatusBadRequest)
+			return
+		}
+		collectionID, err := uuid.Parse(collectionIDStr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid collectionId parameter: %s", collectionIDStr), http.StatusBadRequest)
+			return
+		}
+		selection.CollectionID = &collectionID
+	} else if len(query) > 0 {
+		filter, err := getArticleFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		selection.Filter = &filter
+	}
+
+	digest, err := h.digestSvc.Build(r.Context(), selection, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), exportErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", digest.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, digest.FileName()))
+	if err := digest.Write(w); err != nil {
+		// the status is already sent, the client gets a truncated file
+		log.Printf("Failed to export EPUB digest: %v\n", err)
+	}
+}
+
+// exportErrorStatus maps errors of the export to HTTP status codes
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidExportFormat):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrEmptyDigest), errors.Is(err, service.ErrCollectionNotFound):
+		return http.StatusNotFound
+	default:
+		return articleErrorStatus(err)
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -28,6 +30,17 @@ func (h *FeedHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	handlerutil.JsonResponse(w, feeds)
 }
 
+// GetHealth returns the fetch results, publishing activity and status of all feeds
+func (h *FeedHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	health, err := h.svc.GetHealth(r.Context(), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, health)
+}
+
 func (h *FeedHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -54,12 +67,14 @@ func (h *FeedHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	feed, err := h.svc.Create(r.Context(), &req)
 	if err != nil {
-		switch err {
-		case service.ErrInvalidFeedName, service.ErrInvalidFeedURL:
+		switch {
+		case errors.Is(err, service.ErrInvalidFeedName), errors.Is(err, service.ErrInvalidFeedURL), errors.Is(err, service.ErrInvalidFeedWeight):
 			http.Error(w, err.Error(), http.StatusBadRequest)
-		case service.ErrInvalidRSSFeed, service.ErrFeedValidationFail:
+		case errors.Is(err, service.ErrInvalidRSSFeed), errors.Is(err, service.ErrFeedValidationFail):
 			http.Error(w, err.Error(), http.StatusBadRequest)
-		case service.ErrDuplicateFeedURL:
+		case errors.Is(err, service.ErrUnknownSourceKind), errors.Is(err, service.ErrInvalidSourceConfig):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrDuplicateFeedURL):
 			http.Error(w, err.Error(), http.StatusConflict)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -87,14 +102,16 @@ func (h *FeedHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	feed, err := h.svc.Update(r.Context(), id, &req)
 	if err != nil {
-		switch err {
-		case service.ErrInvalidFeedName, service.ErrInvalidFeedURL:
+		switch {
+		case errors.Is(err, service.ErrInvalidFeedName), errors.Is(err, service.ErrInvalidFeedURL), errors.Is(err, service.ErrInvalidFeedWeight):
 			http.Error(w, err.Error(), http.StatusBadRequest)
-		case service.ErrInvalidRSSFeed, service.ErrFeedValidationFail:
+		case errors.Is(err, service.ErrInvalidRSSFeed), errors.Is(err, service.ErrFeedValidationFail):
 			http.Error(w, err.Error(), http.StatusBadRequest)
-		case service.ErrDuplicateFeedURL:
+		case errors.Is(err, service.ErrUnknownSourceKind), errors.Is(err, service.ErrInvalidSourceConfig):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrDuplicateFeedURL):
 			http.Error(w, err.Error(), http.StatusConflict)
-		case service.ErrFeedNotFound:
+		case errors.Is(err, service.ErrFeedNotFound):
 			http.Error(w, err.Error(), http.StatusNotFound)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -148,3 +165,40 @@ func (h *FeedHandler) UpdateLastReadAt(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *FeedHandler) GetNewArticles(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if legacy {
+		http.Error(w, "from and to are not supported, use cursor and limit", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.GetNewArticles(r.Context(), id, page, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrFeedNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), articleErrorStatus(err))
+		}
+		return
+	}
+
+	handlerutil.JsonResponse(w, articles)
+}
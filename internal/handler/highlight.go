@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type HighlightHandler struct {
+	svc *service.HighlightService
+}
+
+func NewHighlightHandler(svc *service.HighlightService) *HighlightHandler {
+	return &HighlightHandler{svc: svc}
+}
+
+func (h *HighlightHandler) GetByArticleID(w http.ResponseWriter, r *http.Request) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	highlights, err := h.svc.GetByArticleID(r.Context(), articleID)
+	if err != nil {
+		http.Error(w, err.Error(), highlightErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, highlights)
+}
+
+func (h *HighlightHandler) Create(w http.ResponseWriter, r *http.Request) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.CreateHighlightRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	highlight, err := h.svc.Create(r.Context(), articleID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), highlightErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, highlight)
+}
+
+func (h *HighlightHandler) Update(w http.ResponseWriter, r *http.Request) {
+	articleID, highlightID, err := getHighlightParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateHighlightRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	highlight, err := h.svc.Update(r.Context(), articleID, highlightID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), highlightErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, highlight)
+}
+
+func (h *HighlightHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	articleID, highlightID, err := getHighlightParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Delete(r.Context(), articleID, highlightID)
+	if err != nil {
+		http.Error(w, err.Error(), highlightErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HighlightHandler) UpdateArticleNote(w http.ResponseWriter, r *http.Request) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateArticleNoteRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.svc.UpdateArticleNote(r.Context(), articleID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), highlightErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, article)
+}
+
+func (h *HighlightHandler) ExportMarkdown(w http.ResponseWriter, r *http.Request) {
+	markdown, err := h.svc.ExportMarkdown(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="highlights.md"`)
+	w.Write(markdown)
+}
+
+func getHighlightParams(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid article ID")
+	}
+	highlightID, err := uuid.Parse(chi.URLParam(r, "highlightId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid highlight ID")
+	}
+	return articleID, highlightID, nil
+}
+
+// highlightErrorStatus maps errors of the highlight service to HTTP status codes
+func highlightErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidHighlight):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrHighlightNotFound), errors.Is(err, service.ErrArticleNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
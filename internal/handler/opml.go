@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+// maxOPMLSize is the maximum size of an uploaded OPML file in bytes
+const maxOPMLSize = 5 << 20
+
+type OPMLHandler struct {
+	svc *service.OPMLService
+}
+
+func NewOPMLHandler(svc *service.OPMLService) *OPMLHandler {
+	return &OPMLHandler{svc: svc}
+}
+
+// Import imports the OPML file sent as request body or as the file field of a multipart form
+func (h *OPMLHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxOPMLSize)
+
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Missing file field in form", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.svc.Import(r.Context(), body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			http.Error(w, fmt.Sprintf("OPML file is larger than %d bytes", maxOPMLSize), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, service.ErrInvalidOPML):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	handlerutil.JsonResponse(w, result)
+}
+
+// Export returns all feeds as an OPML file download
+func (h *OPMLHandler) Export(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	var b bytes.Buffer
+	if err := h.svc.Export(r.Context(), &b, now); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="subscriptions-%s.opml"`, now.Format(time.DateOnly)))
+	w.Write(b.Bytes())
+}
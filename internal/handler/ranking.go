@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type RankingHandler struct {
+	svc *service.RankingService
+}
+
+func NewRankingHandler(svc *service.RankingService) *RankingHandler {
+	return &RankingHandler{svc: svc}
+}
+
+func (h *RankingHandler) GetForYou(w http.ResponseWriter, r *http.Request) {
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if legacy || page.Cursor != "" || page.IncludeTotal {
+		http.Error(w, "For You only supports the limit parameter", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getArticleFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	articles, err := h.svc.ForYou(r.Context(), page.Limit, filter)
+	if err != nil {
+		http.Error(w, err.Error(), articleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, articles)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type RetentionHandler struct {
+	svc *service.RetentionService
+}
+
+func NewRetentionHandler(svc *service.RetentionService) *RetentionHandler {
+	return &RetentionHandler{svc: svc}
+}
+
+func (h *RetentionHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.svc.GetPolicies(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, policies)
+}
+
+func (h *RetentionHandler) UpdateGlobalPolicy(w http.ResponseWriter, r *http.Request) {
+	var req model.UpdateRetentionPolicyRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.svc.UpdateGlobalPolicy(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), retentionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, policy)
+}
+
+func (h *RetentionHandler) UpdateFeedPolicy(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedId"))
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateRetentionPolicyRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	policy, err := h.svc.UpdateFeedPolicy(r.Context(), feedID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), retentionErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, policy)
+}
+
+func (h *RetentionHandler) DeleteFeedPolicy(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "feedId"))
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.DeleteFeedPolicy(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, err.Error(), retentionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RetentionHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.DryRun(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, result)
+}
+
+func (h *RetentionHandler) GetRuns(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.svc.GetRuns(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, runs)
+}
+
+// retentionErrorStatus maps errors of the retention service to HTTP status codes
+func retentionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRetentionPolicy):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrRetentionPolicyNotFound), errors.Is(err, service.ErrFeedNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type RuleHandler struct {
+	svc *service.RuleService
+}
+
+func NewRuleHandler(svc *service.RuleService) *RuleHandler {
+	return &RuleHandler{svc: svc}
+}
+
+func (h *RuleHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, rules)
+}
+
+func (h *RuleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), ruleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, rule)
+}
+
+func (h *RuleHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateRuleRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), ruleErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, rule)
+}
+
+func (h *RuleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateRuleRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.svc.Update(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), ruleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, rule)
+}
+
+func (h *RuleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Delete(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), ruleErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RuleHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateRuleRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.svc.DryRun(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), ruleErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, result)
+}
+
+// ruleErrorStatus maps errors of the rule service to HTTP status codes
+func ruleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidRule):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrRuleNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type ShareHandler struct {
+	svc *service.ShareService
+}
+
+func NewShareHandler(svc *service.ShareService) *ShareHandler {
+	return &ShareHandler{svc: svc}
+}
+
+func (h *ShareHandler) GetActive(w http.ResponseWriter, r *http.Request) {
+	shares, err := h.svc.GetActive(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, shares)
+}
+
+func (h *ShareHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateShareRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	share, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), shareErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, share)
+}
+
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid share ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Revoke(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), shareErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// View serves the public page of a share, as JSON if requested with format=json or an Accept header
+// for JSON and as HTML otherwise. Responses are not cached, so revoked shares disappear immediately.
+func (h *ShareHandler) View(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	content, err := h.svc.GetContent(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		http.Error(w, err.Error(), shareErrorStatus(err))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" || strings.HasPrefix(r.Header.Get("Accept"), "application/json") {
+		handlerutil.JsonResponse(w, content)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src http: https:; style-src 'unsafe-inline'")
+	if err := h.svc.WritePage(w, content); err != nil {
+		log.Printf("Failed to write share page: %v\n", err)
+	}
+}
+
+// shareErrorStatus maps errors of the share service to HTTP status codes
+func shareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidShare):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrShareNotFound), errors.Is(err, service.ErrArticleNotFound),
+		errors.Is(err, service.ErrCollectionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
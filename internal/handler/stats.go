@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type StatsHandler struct {
+	svc *service.StatsService
+}
+
+func NewStatsHandler(svc *service.StatsService) *StatsHandler {
+	return &StatsHandler{svc: svc}
+}
+
+func (h *StatsHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	req, err := getStatsRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.svc.GetOverview(r.Context(), req, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), statsErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, series)
+}
+
+func (h *StatsHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+	req, err := getStatsRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.svc.GetFeed(r.Context(), feedID, req, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), statsErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, series)
+}
+
+// getStatsRequest parses the optional interval, from, to and tz parameters of the statistics endpoints
+func getStatsRequest(r *http.Request) (model.StatsRequest, error) {
+	req := model.StatsRequest{
+		Interval: model.StatsInterval(r.URL.Query().Get("interval")),
+		TimeZone: r.URL.Query().Get("tz"),
+	}
+	var err error
+	if req.From, err = getOptionalTimeParam(r, "from"); err != nil {
+		return req, err
+	}
+	if req.To, err = getOptionalTimeParam(r, "to"); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// statsErrorStatus maps errors of the statistics service to HTTP status codes
+func statsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidStatsRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrFeedNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
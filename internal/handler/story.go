@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type StoryHandler struct {
+	svc *service.StoryService
+}
+
+func NewStoryHandler(svc *service.StoryService) *StoryHandler {
+	return &StoryHandler{svc: svc}
+}
+
+func (h *StoryHandler) GetPaginated(w http.ResponseWriter, r *http.Request) {
+	from, to, err := getPaginationParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stories, err := h.svc.GetPaginated(r.Context(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, stories)
+}
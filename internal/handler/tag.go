@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type TagHandler struct {
+	svc *service.TagService
+}
+
+func NewTagHandler(svc *service.TagService) *TagHandler {
+	return &TagHandler{svc: svc}
+}
+
+func (h *TagHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.svc.GetAll(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	handlerutil.JsonResponse(w, tags)
+}
+
+func (h *TagHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, tag)
+}
+
+func (h *TagHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateTagRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.svc.Create(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	handlerutil.JsonResponse(w, tag)
+}
+
+func (h *TagHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.UpdateTagRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.svc.Rename(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, tag)
+}
+
+func (h *TagHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.Delete(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return
+	}
+
+	var req model.MergeTagsRequest
+	if err := handlerutil.ParseJsonBody(r, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := h.svc.Merge(r.Context(), id, &req)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, tag)
+}
+
+func (h *TagHandler) AddToArticle(w http.ResponseWriter, r *http.Request) {
+	articleID, tagID, err := getArticleTagParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.AddToArticle(r.Context(), articleID, tagID)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) RemoveFromArticle(w http.ResponseWriter, r *http.Request) {
+	articleID, tagID, err := getArticleTagParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.RemoveFromArticle(r.Context(), articleID, tagID)
+	if err != nil {
+		http.Error(w, err.Error(), tagErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func getArticleTagParams(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	articleID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid article ID")
+	}
+	tagID, err := uuid.Parse(chi.URLParam(r, "tagId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid tag ID")
+	}
+	return articleID, tagID, nil
+}
+
+// tagErrorStatus maps errors of the tag service to HTTP status codes
+func tagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidTagName), errors.Is(err, service.ErrInvalidTagMerge):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrTagNotFound), errors.Is(err, service.ErrArticleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDuplicateTagName):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
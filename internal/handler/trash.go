@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/handlerutil"
+	"github.com/lucasg04/fyrss-server/internal/service"
+)
+
+type TrashHandler struct {
+	svc *service.TrashService
+}
+
+func NewTrashHandler(svc *service.TrashService) *TrashHandler {
+	return &TrashHandler{svc: svc}
+}
+
+func (h *TrashHandler) Get(w http.ResponseWriter, r *http.Request) {
+	page, legacy, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if legacy {
+		http.Error(w, "from and to are not supported, use cursor and limit", http.StatusBadRequest)
+		return
+	}
+
+	trash, err := h.svc.Get(r.Context(), page)
+	if err != nil {
+		http.Error(w, err.Error(), trashErrorStatus(err))
+		return
+	}
+
+	handlerutil.JsonResponse(w, trash)
+}
+
+func (h *TrashHandler) RestoreFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid feed ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.RestoreFeed(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), trashErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TrashHandler) RestoreArticle(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid article ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.svc.RestoreArticle(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), trashErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// trashErrorStatus maps errors of the trash service to HTTP status codes
+func trashErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidPagination):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotInTrash):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
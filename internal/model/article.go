@@ -4,26 +4,119 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-var DefaultNilTime time.Time
+const (
+	SourceTypeRSS     = "rss"
+	SourceTypeScraped = "scraped"
+)
 
 type Article struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Title       string    `json:"title" db:"title"`
 	Description string    `json:"description" db:"description"`
-	ContentHash string    `json:"-" db:"content_hash"`
-	SourceUrl   string    `json:"sourceUrl" db:"source_url"`
-	// SourceType indicates the type of source. "rss" or "scraped"
-	SourceType  string     `json:"sourceType" db:"source_type"`
-	PublishedAt time.Time  `json:"publishedAt" db:"published_at"`
-	LastReadAt  time.Time  `json:"lastReadAt" db:"last_read_at"`
-	Save        bool       `json:"save" db:"save"`
-	FeedID      *uuid.UUID `json:"feedId,omitempty" db:"feed_id"`
+	// Content is the full content of the article if the source provides it
+	Content     string `json:"content,omitempty" db:"content"`
+	ContentHash string `json:"-" db:"content_hash"`
+	SourceUrl   string `json:"sourceUrl" db:"source_url"`
+	// SourceType indicates the type of source. SourceTypeRSS or SourceTypeScraped
+	SourceType  string    `json:"sourceType" db:"source_type"`
+	PublishedAt time.Time `json:"publishedAt" db:"published_at"`
+	// LastReadAt is the time the article was marked as read, nil if it is unread
+	LastReadAt *time.Time `json:"lastReadAt" db:"last_read_at"`
+	// OpenedAt is the time the article was last opened, independent of its read state
+	OpenedAt *time.Time `json:"openedAt" db:"opened_at"`
+	Save     bool       `json:"save" db:"save"`
+	FeedID   *uuid.UUID `json:"feedId,omitempty" db:"feed_id"`
+	// Language is the ISO 639-1 code of the article language or empty if unknown
+	Language  string `json:"language" db:"language"`
+	WordCount int    `json:"wordCount" db:"word_count"`
+	// ReadingTime is the estimated reading time in minutes
+	ReadingTime int `json:"readingTime" db:"reading_time"`
+	// SimHash is the fingerprint of title and description used to find near-duplicate articles
+	SimHash        int64      `json:"-" db:"simhash"`
+	StoryClusterID *uuid.UUID `json:"storyClusterId,omitempty" db:"story_cluster_id"`
+	// DwellSeconds is the total time the article was open in the client
+	DwellSeconds int `json:"dwellSeconds" db:"dwell_seconds"`
+	// Author and Categories are taken from the source item, they are matched by rules
+	Author     string         `json:"author" db:"author"`
+	Categories pq.StringArray `json:"categories" db:"categories"`
+	// Priority is set by rules, higher is more important
+	Priority int `json:"priority" db:"priority"`
+	// Note is the free-form note of the article, empty if there is none
+	Note string `json:"note" db:"note"`
+	// DeletedAt is the time the article was moved to the trash, nil if it is not trashed
+	DeletedAt *time.Time    `json:"deletedAt,omitempty" db:"deleted_at"`
+	Tags      []*ArticleTag `json:"tags,omitempty" db:"-"`
+	// Feed is only set by the timeline, other endpoints return just the FeedID
+	Feed *ArticleFeed `json:"feed,omitempty" db:"-"`
+}
+
+// ArticleSort defines the order of article lists. An empty sort uses the default order of the endpoint.
+type ArticleSort string
+
+const (
+	ArticleSortNewest   ArticleSort = "newest"
+	ArticleSortOldest   ArticleSort = "oldest"
+	ArticleSortShortest ArticleSort = "shortest"
+	ArticleSortLongest  ArticleSort = "longest"
+	// ArticleSortRecentlyRead orders by last read date, it is the default order of the history
+	ArticleSortRecentlyRead ArticleSort = "recentlyRead"
+	// ArticleSortPriority orders by the priority set by rules, then by newest
+	ArticleSortPriority ArticleSort = "priority"
+)
+
+// ArticleFilter restricts and orders the articles of list endpoints. Zero values don't filter.
+type ArticleFilter struct {
+	FeedIDs []uuid.UUID
+	// TagIDs matches articles with any of the tags
+	TagIDs     []uuid.UUID
+	Language   string
+	SourceType string
+	// TitleContains is a case-insensitive substring of the title
+	TitleContains string
+	Saved         *bool
+	Read          *bool
+	// PublishedFrom and PublishedTo are inclusive bounds of the publish date
+	PublishedFrom *time.Time
+	PublishedTo   *time.Time
+	// PublishedAfter is an exclusive lower bound of the publish date
+	PublishedAfter *time.Time
+	// ReadFrom and ReadTo are inclusive bounds of the last read date, they only match read articles
+	ReadFrom *time.Time
+	ReadTo   *time.Time
+	// MinReadingTime and MaxReadingTime are inclusive bounds in minutes
+	MinReadingTime *int
+	MaxReadingTime *int
+	Sort           ArticleSort
+	// CollapseClusters only includes the representative article of each story cluster
+	CollapseClusters bool
+}
+
+// ArticleCursor is the position after the last article of a page. It holds the sort keys of that article.
+type ArticleCursor struct {
+	Sort        ArticleSort `json:"s"`
+	PublishedAt time.Time   `json:"p"`
+	LastReadAt  *time.Time  `json:"r,omitempty"`
+	ReadingTime int         `json:"t"`
+	Priority    int         `json:"y,omitempty"`
+	ID          uuid.UUID   `json:"i"`
+}
+
+// ArticlePage is a page of an article list
+type ArticlePage struct {
+	Articles []*Article `json:"articles"`
+	// NextCursor is the opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+	// TotalCount is the number of articles of all pages, only set if requested
+	TotalCount *int `json:"totalCount,omitempty"`
 }
 
-type MinimalFeedArticle struct {
-	ID          uuid.UUID `db:"id"`
-	Description string    `db:"description"`
-	PublishedAt time.Time `db:"published_at"`
+// ArticleSearchResult is an article matching a full-text search query
+type ArticleSearchResult struct {
+	Article
+	Rank float64 `json:"rank" db:"rank"`
+	// Snippet is an excerpt of the article with matches enclosed in <mark> tags
+	Snippet string `json:"snippet" db:"snippet"`
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkArticleAction is the change applied by a bulk operation to each selected article
+type BulkArticleAction string
+
+const (
+	BulkActionRead   BulkArticleAction = "read"
+	BulkActionUnread BulkArticleAction = "unread"
+	BulkActionSave   BulkArticleAction = "save"
+	BulkActionUnsave BulkArticleAction = "unsave"
+	BulkActionDelete BulkArticleAction = "delete"
+)
+
+// BulkArticleRequest applies an action to the articles with the given IDs or to the articles matching the filter
+type BulkArticleRequest struct {
+	IDs    []uuid.UUID        `json:"ids,omitempty"`
+	Filter *BulkArticleFilter `json:"filter,omitempty"`
+	Action BulkArticleAction  `json:"action"`
+}
+
+// BulkArticleFilter selects the articles of a bulk operation. All set criteria must match.
+type BulkArticleFilter struct {
+	FeedID *uuid.UUID `json:"feedId,omitempty"`
+	// OlderThan only selects articles published before this time
+	OlderThan *time.Time `json:"olderThan,omitempty"`
+	// Query is a full-text search query in the syntax of the search endpoint
+	Query string `json:"query,omitempty"`
+}
+
+// BulkArticleResult reports the outcome of a bulk operation
+type BulkArticleResult struct {
+	Action BulkArticleAction `json:"action"`
+	// Matched is the number of selected articles
+	Matched int `json:"matched"`
+	// Affected is the number of articles which were changed, articles already in the target state are not counted
+	Affected int `json:"affected"`
+}
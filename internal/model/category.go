@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Category struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Position  int       `json:"position" db:"position"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	// UnreadCount and NewSinceLastVisit are the sums over the feeds of the category
+	UnreadCount       int     `json:"unreadCount" db:"-"`
+	NewSinceLastVisit int     `json:"newSinceLastVisit" db:"-"`
+	Feeds             []*Feed `json:"feeds" db:"-"`
+}
+
+type CreateCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+// ReorderCategoriesRequest lists the IDs of all categories in their new order
+type ReorderCategoriesRequest struct {
+	CategoryIDs []uuid.UUID `json:"categoryIds"`
+}
+
+// SetCategoryFeedsRequest lists the feeds of a category in their order.
+// Feeds of other categories are moved, feeds of the category which are not listed become uncategorized.
+type SetCategoryFeedsRequest struct {
+	FeedIDs []uuid.UUID `json:"feedIds"`
+}
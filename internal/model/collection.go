@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection is a named, manually ordered list of articles
+type Collection struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	// IsDefault marks the "Saved" collection, which contains the articles with Save set
+	IsDefault    bool      `json:"isDefault" db:"is_default"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+	ArticleCount int       `json:"articleCount" db:"article_count"`
+}
+
+// CollectionArticle is an article at its position in a collection
+type CollectionArticle struct {
+	Article
+	Position int       `json:"position" db:"collection_position"`
+	AddedAt  time.Time `json:"addedAt" db:"collection_added_at"`
+}
+
+// CollectionArticlePage is a page of the articles of a collection in their order
+type CollectionArticlePage struct {
+	Articles []*CollectionArticle `json:"articles"`
+	// NextCursor is the opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type UpdateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// MoveCollectionArticleRequest moves an article to another position or collection
+type MoveCollectionArticleRequest struct {
+	// CollectionID is the target collection, nil keeps the article in its collection
+	CollectionID *uuid.UUID `json:"collectionId,omitempty"`
+	// Position is the index in the target collection, nil moves the article to the end
+	Position *int `json:"position,omitempty"`
+}
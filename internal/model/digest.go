@@ -0,0 +1,11 @@
+package model
+
+import "github.com/google/uuid"
+
+// DigestSelection selects the articles of an EPUB digest. If neither is set, the digest holds today's unread articles.
+type DigestSelection struct {
+	// CollectionID selects the articles of a collection in their order
+	CollectionID *uuid.UUID
+	// Filter selects the articles matching the filter, oldest first unless it has a sort
+	Filter *ArticleFilter
+}
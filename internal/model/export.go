@@ -0,0 +1,11 @@
+package model
+
+// ExportFormat is the file format of an article export
+type ExportFormat string
+
+const (
+	ExportFormatJSON     ExportFormat = "json"
+	ExportFormatCSV      ExportFormat = "csv"
+	ExportFormatMarkdown ExportFormat = "md"
+	ExportFormatHTML     ExportFormat = "html"
+)
@@ -1,27 +1,97 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// SourceKindRSS is the source kind for RSS/Atom/JSON feeds parsed by gofeed.
+const SourceKindRSS = "rss"
+
 type Feed struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	Name         string    `json:"name" db:"name"`
-	URL          string    `json:"url" db:"url"`
-	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
-	LastReadAt   time.Time `json:"lastReadAt" db:"last_read_at"`
-	ArticleCount int       `json:"articleCount" db:"-"`
+	ID           uuid.UUID    `json:"id" db:"id"`
+	Name         string       `json:"name" db:"name"`
+	URL          string       `json:"url" db:"url"`
+	SourceKind   string       `json:"sourceKind" db:"source_kind"`
+	SourceConfig SourceConfig `json:"sourceConfig" db:"source_config"`
+	// Weight scales the score of the feed's articles in the For You ranking, 1 is neutral and 0 hides them
+	Weight float64 `json:"weight" db:"weight"`
+	// CategoryID is nil for uncategorized feeds, Position orders the feeds within their category
+	CategoryID *uuid.UUID `json:"categoryId" db:"category_id"`
+	Position   int        `json:"position" db:"position"`
+	// IconURL is the image of the feed or the favicon of its site, nil if unknown
+	IconURL *string `json:"iconUrl" db:"icon_url"`
+	// DeletedAt is the time the feed was moved to the trash, nil if it is not trashed
+	DeletedAt    *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+	LastReadAt   time.Time  `json:"lastReadAt" db:"last_read_at"`
+	ArticleCount int        `json:"articleCount" db:"article_count"`
+	UnreadCount  int        `json:"unreadCount" db:"unread_count"`
+	// NewSinceLastVisit counts the articles published after LastReadAt
+	NewSinceLastVisit int `json:"newSinceLastVisit" db:"new_since_last_visit"`
+}
+
+// SourceConfig holds kind specific settings of a feed source. It is stored as JSONB.
+type SourceConfig map[string]any
+
+func (c SourceConfig) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal source config: %w", err)
+	}
+	return string(data), nil
+}
+
+func (c *SourceConfig) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*c = SourceConfig{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for source config: %T", src)
+	}
+	config := SourceConfig{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to unmarshal source config: %w", err)
+	}
+	*c = config
+	return nil
+}
+
+// ArticleFeed is the feed of an article as embedded in timeline items
+type ArticleFeed struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	Name    string    `json:"name" db:"name"`
+	IconURL *string   `json:"iconUrl" db:"icon_url"`
 }
 
 type CreateFeedRequest struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name         string       `json:"name"`
+	URL          string       `json:"url"`
+	SourceKind   string       `json:"sourceKind"`
+	SourceConfig SourceConfig `json:"sourceConfig"`
+	// Weight defaults to 1 if omitted
+	Weight *float64 `json:"weight,omitempty"`
 }
 
 type UpdateFeedRequest struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name         string       `json:"name"`
+	URL          string       `json:"url"`
+	SourceKind   string       `json:"sourceKind"`
+	SourceConfig SourceConfig `json:"sourceConfig"`
+	// Weight is left unchanged if omitted
+	Weight *float64 `json:"weight,omitempty"`
 }
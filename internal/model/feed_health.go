@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedHealthStatus summarizes whether a feed still works and publishes
+type FeedHealthStatus string
+
+const (
+	FeedHealthStatusHealthy FeedHealthStatus = "healthy"
+	// FeedHealthStatusStale feeds are fetched successfully but have not published new articles for a while
+	FeedHealthStatusStale FeedHealthStatus = "stale"
+	// FeedHealthStatusFailing feeds failed several fetches in a row or their last validation
+	FeedHealthStatusFailing FeedHealthStatus = "failing"
+	// FeedHealthStatusDead feeds are failing and have not been fetched successfully for a long time
+	FeedHealthStatusDead FeedHealthStatus = "dead"
+)
+
+// FeedHealth holds the fetch results and the publishing activity of a feed
+type FeedHealth struct {
+	FeedID uuid.UUID        `json:"feedId" db:"feed_id"`
+	Name   string           `json:"name" db:"name"`
+	URL    string           `json:"url" db:"url"`
+	Status FeedHealthStatus `json:"status" db:"-"`
+	// LastSuccessAt is the time of the last successful fetch, nil if the feed was never fetched successfully
+	LastSuccessAt *time.Time `json:"lastSuccessAt" db:"last_success_at"`
+	// LastError is the error of the last failed fetch, it is kept after the feed recovers
+	LastFailureAt       *time.Time `json:"lastFailureAt" db:"last_failure_at"`
+	LastError           string     `json:"lastError" db:"last_error"`
+	ConsecutiveFailures int        `json:"consecutiveFailures" db:"consecutive_failures"`
+	// ValidationError is the error of the last weekly validation, empty if it succeeded
+	LastValidatedAt *time.Time `json:"lastValidatedAt" db:"last_validated_at"`
+	ValidationError string     `json:"validationError" db:"validation_error"`
+	// AvgItemsPerDay is the number of articles per day over the last 30 days, or since the feed was added
+	AvgItemsPerDay float64 `json:"avgItemsPerDay" db:"-"`
+	// DaysSinceLastItem is the number of days since the newest article was published, nil if there is none
+	DaysSinceLastItem *int       `json:"daysSinceLastItem" db:"-"`
+	LastPublishedAt   *time.Time `json:"lastPublishedAt" db:"last_published_at"`
+	RecentCount       int        `json:"-" db:"recent_count"`
+	CreatedAt         time.Time  `json:"-" db:"created_at"`
+}
+
+// FeedValidationResult counts the feeds checked by a validation run
+type FeedValidationResult struct {
+	Validated int `json:"validated"`
+	Failed    int `json:"failed"`
+}
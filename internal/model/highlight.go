@@ -0,0 +1,71 @@
+package model
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HighlightColor string
+
+const (
+	HighlightColorYellow HighlightColor = "yellow"
+	HighlightColorGreen  HighlightColor = "green"
+	HighlightColorBlue   HighlightColor = "blue"
+	HighlightColorPink   HighlightColor = "pink"
+	HighlightColorPurple HighlightColor = "purple"
+)
+
+// Highlight is a highlighted quote of an article
+type Highlight struct {
+	ID        uuid.UUID       `json:"id" db:"id"`
+	ArticleID uuid.UUID       `json:"articleId" db:"article_id"`
+	Quote     string          `json:"quote" db:"quote"`
+	Anchor    HighlightAnchor `json:"anchor" db:"anchor"`
+	Color     HighlightColor  `json:"color" db:"color"`
+	Note      string          `json:"note" db:"note"`
+	CreatedAt time.Time       `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time       `json:"updatedAt" db:"updated_at"`
+}
+
+// HighlightAnchor locates the quote in the text of the article. Start and End are character offsets,
+// Prefix and Suffix are the text around the quote to find it again if the text changed. It is stored as JSONB.
+type HighlightAnchor struct {
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+func (a HighlightAnchor) Value() (driver.Value, error) {
+	return jsonValue(a)
+}
+
+func (a *HighlightAnchor) Scan(src any) error {
+	return scanJSON(src, a)
+}
+
+type CreateHighlightRequest struct {
+	Quote  string          `json:"quote"`
+	Anchor HighlightAnchor `json:"anchor"`
+	// Color defaults to yellow if omitted
+	Color HighlightColor `json:"color"`
+	Note  string         `json:"note"`
+}
+
+// UpdateHighlightRequest changes the color and note, the quote and its anchor are fixed
+type UpdateHighlightRequest struct {
+	Color HighlightColor `json:"color"`
+	Note  string         `json:"note"`
+}
+
+type UpdateArticleNoteRequest struct {
+	Note string `json:"note"`
+}
+
+// AnnotatedArticle is an article with its highlights as exported to Markdown
+type AnnotatedArticle struct {
+	Article    *Article
+	Highlights []*Highlight
+}
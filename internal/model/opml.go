@@ -0,0 +1,39 @@
+package model
+
+import "github.com/google/uuid"
+
+// OPMLImportStatus is the outcome of importing one feed of an OPML file
+type OPMLImportStatus string
+
+const (
+	OPMLImportStatusImported  OPMLImportStatus = "imported"
+	OPMLImportStatusDuplicate OPMLImportStatus = "duplicate"
+	OPMLImportStatusInvalid   OPMLImportStatus = "invalid"
+	// OPMLImportStatusCancelled marks feeds that could not be validated before the import timed out
+	OPMLImportStatusCancelled OPMLImportStatus = "cancelled"
+	// OPMLImportStatusFailed marks valid feeds that could not be created
+	OPMLImportStatusFailed OPMLImportStatus = "failed"
+)
+
+// OPMLImportEntry reports the import of one feed outline
+type OPMLImportEntry struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	// Category is the name of the folder the feed was listed in, empty for feeds outside of folders
+	Category string           `json:"category,omitempty"`
+	Status   OPMLImportStatus `json:"status"`
+	// Error explains why a feed was not imported, or why an imported feed was not added to its category
+	Error string `json:"error,omitempty"`
+	// FeedID is the ID of the created feed, only set for imported feeds
+	FeedID *uuid.UUID `json:"feedId,omitempty"`
+}
+
+// OPMLImportResult reports the import of an OPML file with an entry per feed in the order of the file
+type OPMLImportResult struct {
+	Imported   int                `json:"imported"`
+	Duplicates int                `json:"duplicates"`
+	Invalid    int                `json:"invalid"`
+	Cancelled  int                `json:"cancelled"`
+	Failed     int                `json:"failed"`
+	Entries    []*OPMLImportEntry `json:"entries"`
+}
@@ -0,0 +1,11 @@
+package model
+
+// PageRequest selects a page of a list endpoint
+type PageRequest struct {
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	Limit  int
+	// Offset is only used by the deprecated from/to pagination, it cannot be combined with a cursor
+	Offset       int
+	IncludeTotal bool
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedEngagement summarizes how the articles of a feed were read
+type FeedEngagement struct {
+	FeedID       uuid.UUID `db:"feed_id"`
+	Weight       float64   `db:"weight"`
+	ArticleCount int       `db:"article_count"`
+	ReadCount    int       `db:"read_count"`
+	SavedCount   int       `db:"saved_count"`
+	// DwellCount is the number of articles with reported dwell time
+	DwellCount int `db:"dwell_count"`
+	// DwellRatio is the average share of the estimated reading time the articles were open, between 0 and 1
+	DwellRatio float64 `db:"dwell_ratio"`
+}
+
+// FeedScore is the part of the For You score that only depends on the feed
+type FeedScore struct {
+	FeedID uuid.UUID
+	Weight float64
+	// Signals is the weighted sum of the feed affinity and dwell signals
+	Signals float64
+}
+
+// RankingQuery lets the database order the For You candidates by the same score as the ranking
+type RankingQuery struct {
+	Feeds []*FeedScore
+	// DefaultSignals is used for articles of feeds without a score, which have a weight of 1
+	DefaultSignals  float64
+	RecencyWeight   float64
+	RecencyHalfLife time.Duration
+	Now             time.Time
+}
+
+// RankedArticle is an article of the For You ranking
+type RankedArticle struct {
+	Article
+	Rank        int                `json:"rank"`
+	Score       float64            `json:"score"`
+	Explanation RankingExplanation `json:"explanation"`
+}
+
+// RankingExplanation shows the signals the score of a ranked article is made of.
+// Recency, FeedAffinity and Dwell are between 0 and 1, their weighted sum is scaled by FeedWeight.
+type RankingExplanation struct {
+	Recency      float64 `json:"recency"`
+	FeedAffinity float64 `json:"feedAffinity"`
+	Dwell        float64 `json:"dwell"`
+	FeedWeight   float64 `json:"feedWeight"`
+	// Reasons describe the signals in words, the strongest first
+	Reasons []string `json:"reasons"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RetentionPolicy decides which articles the cleanup moves to the trash. A feed with its own policy uses it instead of the
+// global policy. Saved, tagged, annotated and collected articles are never deleted.
+type RetentionPolicy struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	// FeedID is nil for the global policy
+	FeedID *uuid.UUID `json:"feedId" db:"feed_id"`
+	// MaxAgeDays deletes articles published longer ago, nil keeps articles regardless of their age
+	MaxAgeDays *int `json:"maxAgeDays" db:"max_age_days"`
+	// KeepLatest keeps the newest articles of each feed even if they are older than the max age
+	KeepLatest *int `json:"keepLatest" db:"keep_latest"`
+	// UnreadMaxAgeDays replaces the max age for unread articles, nil uses the max age
+	UnreadMaxAgeDays *int      `json:"unreadMaxAgeDays" db:"unread_max_age_days"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt        time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type UpdateRetentionPolicyRequest struct {
+	MaxAgeDays       *int `json:"maxAgeDays"`
+	KeepLatest       *int `json:"keepLatest"`
+	UnreadMaxAgeDays *int `json:"unreadMaxAgeDays"`
+}
+
+// RetentionFeedCount is the number of articles of a feed trashed by the cleanup, FeedID is nil for articles
+// without feed
+type RetentionFeedCount struct {
+	FeedID *uuid.UUID `json:"feedId" db:"feed_id"`
+	Count  int        `json:"count" db:"count"`
+}
+
+// RetentionFeedCounts are stored as JSONB
+type RetentionFeedCounts []RetentionFeedCount
+
+func (c RetentionFeedCounts) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *RetentionFeedCounts) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+// RetentionRun records a cleanup run, Error is set if it failed
+type RetentionRun struct {
+	ID           uuid.UUID           `json:"id" db:"id"`
+	StartedAt    time.Time           `json:"startedAt" db:"started_at"`
+	FinishedAt   time.Time           `json:"finishedAt" db:"finished_at"`
+	DeletedCount int                 `json:"deletedCount" db:"deleted_count"`
+	Feeds        RetentionFeedCounts `json:"feeds" db:"feeds"`
+	Error        *string             `json:"error" db:"error"`
+}
+
+// RetentionDryRunResult reports what the cleanup would delete now. Articles lists the oldest of them.
+type RetentionDryRunResult struct {
+	Count    int                 `json:"count"`
+	Feeds    RetentionFeedCounts `json:"feeds"`
+	Articles []*Article          `json:"articles"`
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleField is the part of an article a rule condition is matched against
+type RuleField string
+
+const (
+	RuleFieldTitle RuleField = "title"
+	// RuleFieldContent matches the description and the content
+	RuleFieldContent  RuleField = "content"
+	RuleFieldAuthor   RuleField = "author"
+	RuleFieldCategory RuleField = "category"
+)
+
+// RuleMatch defines how the value of a rule condition is matched
+type RuleMatch string
+
+const (
+	// RuleMatchKeyword matches if the field contains the value, ignoring case
+	RuleMatchKeyword RuleMatch = "keyword"
+	// RuleMatchRegex matches if the regular expression matches the field
+	RuleMatchRegex RuleMatch = "regex"
+)
+
+type RuleActionType string
+
+const (
+	// RuleActionSkip drops the article, it is not saved
+	RuleActionSkip        RuleActionType = "skip"
+	RuleActionMarkRead    RuleActionType = "markRead"
+	RuleActionSave        RuleActionType = "save"
+	RuleActionTag         RuleActionType = "tag"
+	RuleActionSetPriority RuleActionType = "setPriority"
+)
+
+// Rule applies its actions to new articles matching all of its conditions
+type Rule struct {
+	ID   uuid.UUID `json:"id" db:"id"`
+	Name string    `json:"name" db:"name"`
+	// FeedID limits the rule to one feed, nil applies it to all feeds
+	FeedID     *uuid.UUID     `json:"feedId" db:"feed_id"`
+	Conditions RuleConditions `json:"conditions" db:"conditions"`
+	Actions    RuleActions    `json:"actions" db:"actions"`
+	Enabled    bool           `json:"enabled" db:"enabled"`
+	// MatchCount counts the new articles the rule matched during ingestion
+	MatchCount    int        `json:"matchCount" db:"match_count"`
+	LastMatchedAt *time.Time `json:"lastMatchedAt" db:"last_matched_at"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type RuleCondition struct {
+	Field RuleField `json:"field"`
+	Match RuleMatch `json:"match"`
+	Value string    `json:"value"`
+}
+
+type RuleAction struct {
+	Type RuleActionType `json:"type"`
+	// TagID is the tag added by RuleActionTag
+	TagID *uuid.UUID `json:"tagId,omitempty"`
+	// Priority is the priority set by RuleActionSetPriority
+	Priority *int `json:"priority,omitempty"`
+}
+
+// RuleConditions and RuleActions are stored as JSONB
+type RuleConditions []RuleCondition
+
+type RuleActions []RuleAction
+
+func (c RuleConditions) Value() (driver.Value, error) {
+	return jsonValue(c)
+}
+
+func (c *RuleConditions) Scan(src any) error {
+	return scanJSON(src, c)
+}
+
+func (a RuleActions) Value() (driver.Value, error) {
+	return jsonValue(a)
+}
+
+func (a *RuleActions) Scan(src any) error {
+	return scanJSON(src, a)
+}
+
+func jsonValue(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %w", v, err)
+	}
+	return string(data), nil
+}
+
+func scanJSON(src any, dest any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for %T: %T", dest, src)
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %w", dest, err)
+	}
+	return nil
+}
+
+type CreateRuleRequest struct {
+	Name       string         `json:"name"`
+	FeedID     *uuid.UUID     `json:"feedId"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+	// Enabled defaults to true if omitted
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type UpdateRuleRequest struct {
+	Name       string         `json:"name"`
+	FeedID     *uuid.UUID     `json:"feedId"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+	// Enabled is left unchanged if omitted
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// RuleDryRunResult lists the recent articles a rule would have matched
+type RuleDryRunResult struct {
+	Checked  int        `json:"checked"`
+	Matched  int        `json:"matched"`
+	Articles []*Article `json:"articles"`
+}
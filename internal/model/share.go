@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Share is a public read-only link to an article or a collection, identified by its token
+type Share struct {
+	ID    uuid.UUID `json:"id" db:"id"`
+	Token string    `json:"token" db:"token"`
+	// URL is the path of the public page of the share
+	URL string `json:"url" db:"-"`
+	// Exactly one of ArticleID and CollectionID is set
+	ArticleID    *uuid.UUID `json:"articleId,omitempty" db:"article_id"`
+	CollectionID *uuid.UUID `json:"collectionId,omitempty" db:"collection_id"`
+	// Title is the title of the shared article or the name of the shared collection
+	Title     string    `json:"title" db:"title"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	// ExpiresAt is nil if the share does not expire
+	ExpiresAt *time.Time `json:"expiresAt" db:"expires_at"`
+}
+
+type CreateShareRequest struct {
+	ArticleID    *uuid.UUID `json:"articleId,omitempty"`
+	CollectionID *uuid.UUID `json:"collectionId,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SharedContent is the public view of a share. It holds only what is meant to be shared, not the
+// read state, notes or tags of the articles.
+type SharedContent struct {
+	Title     string           `json:"title"`
+	ExpiresAt *time.Time       `json:"expiresAt"`
+	Articles  []*SharedArticle `json:"articles"`
+}
+
+type SharedArticle struct {
+	Title       string    `json:"title"`
+	SourceUrl   string    `json:"sourceUrl"`
+	Feed        string    `json:"feed,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"publishedAt"`
+	// Description is plain text, Content is sanitized HTML
+	Description string `json:"description"`
+	Content     string `json:"content,omitempty"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatsInterval is the size of the buckets of a statistics time series
+type StatsInterval string
+
+const (
+	StatsIntervalHour StatsInterval = "hour"
+	StatsIntervalDay  StatsInterval = "day"
+	StatsIntervalWeek StatsInterval = "week"
+)
+
+// StatsRequest selects the buckets of a time series. Nil times and an empty interval or time zone use the defaults.
+type StatsRequest struct {
+	Interval StatsInterval
+	From     *time.Time
+	To       *time.Time
+	// TimeZone is the IANA name of the time zone the buckets start in, UTC by default
+	TimeZone string
+}
+
+// StatsCounts counts the articles by the time they were published, ingested, read and saved
+type StatsCounts struct {
+	Published int `json:"published"`
+	Ingested  int `json:"ingested"`
+	Read      int `json:"read"`
+	Saved     int `json:"saved"`
+}
+
+type StatsBucket struct {
+	Start time.Time `json:"start"`
+	StatsCounts
+}
+
+// StatsSeries is a time series of article counts, with a bucket for every interval between From and To
+type StatsSeries struct {
+	// FeedID is nil for the overview of all feeds
+	FeedID   *uuid.UUID     `json:"feedId,omitempty"`
+	Interval StatsInterval  `json:"interval"`
+	TimeZone string         `json:"timeZone"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Totals   StatsCounts    `json:"totals"`
+	Buckets  []*StatsBucket `json:"buckets"`
+}
+
+// StatsMetricCount is the number of articles of one metric in a bucket
+type StatsMetricCount struct {
+	Metric string    `db:"metric"`
+	Bucket time.Time `db:"bucket"`
+	Count  int       `db:"count"`
+}
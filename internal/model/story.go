@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoryCluster groups near-duplicate articles of different feeds reporting the same story.
+type StoryCluster struct {
+	ID uuid.UUID `json:"id" db:"id"`
+	// RepresentativeArticleID is the earliest published article of the cluster
+	RepresentativeArticleID *uuid.UUID `json:"representativeArticleId" db:"representative_article_id"`
+	CreatedAt               time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updatedAt" db:"updated_at"`
+	ArticleCount            int        `json:"articleCount" db:"article_count"`
+	Articles                []*Article `json:"articles" db:"-"`
+}
+
+// ClusterCandidate contains the fields of an article needed to assign it to a story cluster.
+type ClusterCandidate struct {
+	ID             uuid.UUID  `db:"id"`
+	FeedID         *uuid.UUID `db:"feed_id"`
+	SimHash        int64      `db:"simhash"`
+	StoryClusterID *uuid.UUID `db:"story_cluster_id"`
+	PublishedAt    time.Time  `db:"published_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Tag struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	ArticleCount int       `json:"articleCount" db:"article_count"`
+}
+
+// ArticleTag is a tag as listed on an article
+type ArticleTag struct {
+	ID   uuid.UUID `json:"id" db:"id"`
+	Name string    `json:"name" db:"name"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateTagRequest struct {
+	Name string `json:"name"`
+}
+
+// MergeTagsRequest moves the articles of the source tags to the target tag and deletes the source tags
+type MergeTagsRequest struct {
+	SourceIDs []uuid.UUID `json:"sourceIds"`
+}
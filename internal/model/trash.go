@@ -0,0 +1,16 @@
+package model
+
+// TrashPage lists the trashed feeds and a page of the trashed articles, most recently trashed first
+type TrashPage struct {
+	Feeds      []*Feed    `json:"feeds"`
+	Articles   []*Article `json:"articles"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	// PurgeAfterDays is the grace period after which trashed feeds and articles are deleted permanently
+	PurgeAfterDays int `json:"purgeAfterDays"`
+}
+
+// TrashPurgeResult counts the feeds and articles deleted permanently by a purge
+type TrashPurgeResult struct {
+	Feeds    int `json:"feeds"`
+	Articles int `json:"articles"`
+}
@@ -3,12 +3,29 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/lucasg04/fyrss-server/internal/model"
 )
 
+// articleColumns lists the columns of model.Article. The generated search_vector column is left out.
+var articleColumns = []string{
+	"id", "title", "description", "content", "content_hash", "source_url", "source_type", "published_at",
+	"last_read_at", "opened_at", "save", "feed_id", "language", "word_count", "reading_time", "simhash", "story_cluster_id",
+	"dwell_seconds", "author", "categories", "priority", "note", "deleted_at",
+}
+
+// selectArticleColumns returns the article columns for a SELECT clause, qualified with the table alias if given
+func selectArticleColumns(alias string) string {
+	if alias == "" {
+		return strings.Join(articleColumns, ", ")
+	}
+	return alias + "." + strings.Join(articleColumns, ", "+alias+".")
+}
+
 type ArticleRepository struct {
 	db *sqlx.DB
 }
@@ -17,10 +34,15 @@ func NewArticleRepository(db *sqlx.DB) *ArticleRepository {
 	return &ArticleRepository{db: db}
 }
 
-func (r *ArticleRepository) GetAll(ctx context.Context) ([]*model.Article, error) {
-	query := "SELECT * FROM articles"
+// GetAll returns all articles matching the filter.
+func (r *ArticleRepository) GetAll(ctx context.Context, filter model.ArticleFilter) ([]*model.Article, error) {
+	var where whereBuilder
+	where.add(notTrashed("articles"))
+	where.addArticleFilter(filter)
+	query := fmt.Sprintf("SELECT %s FROM articles %s ORDER BY %s",
+		selectArticleColumns(""), where.sql(), articleOrderBy(filter.Sort, model.ArticleSortNewest))
 	var articles []*model.Article
-	err := r.db.SelectContext(ctx, &articles, query)
+	err := r.db.SelectContext(ctx, &articles, query, where.args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all articles: %w", err)
 	}
@@ -32,7 +54,7 @@ func (r *ArticleRepository) GetAll(ctx context.Context) ([]*model.Article, error
 }
 
 func (r *ArticleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Article, error) {
-	query := "SELECT * FROM articles WHERE id = $1"
+	query := fmt.Sprintf("SELECT %s FROM articles WHERE id = $1 AND %s", selectArticleColumns(""), notTrashed("articles"))
 	var article model.Article
 	err := r.db.GetContext(ctx, &article, query, id)
 	if err != nil {
@@ -41,30 +63,12 @@ func (r *ArticleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.A
 	return &article, nil
 }
 
-func (r *ArticleRepository) GetAllOfFeedSortedByRecent(ctx context.Context, feedID uuid.UUID) ([]*model.MinimalFeedArticle, error) {
-	query := `
-		SELECT id, description, published_at
-		FROM articles
-		WHERE feed_id = $1
-		ORDER BY published_at DESC, id DESC`
-	var articles []*model.MinimalFeedArticle
-	err := r.db.SelectContext(ctx, &articles, query, feedID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get all articles sorted by recent: %w", err)
-	}
-	// Ensure empty slice, not nil, if no results
-	if articles == nil {
-		articles = []*model.MinimalFeedArticle{}
-	}
-	return articles, nil
-}
-
 func (r *ArticleRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Article, error) {
 	if len(ids) == 0 {
 		return []*model.Article{}, nil
 	}
 
-	query, args, err := sqlx.In("SELECT * FROM articles WHERE id IN (?)", ids)
+	query, args, err := sqlx.In(fmt.Sprintf("SELECT %s FROM articles WHERE id IN (?) AND %s", selectArticleColumns(""), notTrashed("articles")), ids)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare query for article IDs: %w", err)
 	}
@@ -79,58 +83,173 @@ func (r *ArticleRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*m
 	return articles, nil
 }
 
-func (r *ArticleRepository) GetFullHistorySorted(ctx context.Context) ([]*model.MinimalFeedArticle, error) {
-	query := `
-		SELECT id, description, published_at
-		FROM articles
-		WHERE last_read_at != $1
-		ORDER BY last_read_at DESC, id DESC`
-	var articles []*model.MinimalFeedArticle
-	err := r.db.SelectContext(ctx, &articles, query, model.DefaultNilTime)
+// GetPage returns up to limit articles matching the filter in the order of its sort.
+// The page starts after the cursor or, without a cursor, at the offset.
+func (r *ArticleRepository) GetPage(ctx context.Context, filter model.ArticleFilter, cursor *model.ArticleCursor, offset, limit int) ([]*model.Article, error) {
+	var where whereBuilder
+	where.add(notTrashed("articles"))
+	where.addArticleFilter(filter)
+	columns := articleSort(filter.Sort, model.ArticleSortNewest)
+	if cursor != nil {
+		where.addKeyset(columns, *cursor)
+	}
+	query := fmt.Sprintf("SELECT %s FROM articles %s ORDER BY %s LIMIT %s",
+		selectArticleColumns(""), where.sql(), orderBy(columns), where.arg(limit))
+	if cursor == nil && offset > 0 {
+		query += " OFFSET " + where.arg(offset)
+	}
+
+	var articles []*model.Article
+	err := r.db.SelectContext(ctx, &articles, query, where.args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get article history: %w", err)
+		return nil, fmt.Errorf("failed to get page of articles: %w", err)
 	}
 	// Ensure empty slice, not nil, if no results
 	if articles == nil {
-		articles = []*model.MinimalFeedArticle{}
+		articles = []*model.Article{}
 	}
 	return articles, nil
 }
 
-func (r *ArticleRepository) GetAllSavedSorted(ctx context.Context) ([]*model.MinimalFeedArticle, error) {
-	query := `
-		SELECT id, description, published_at
-		FROM articles
-		WHERE save = true
-		ORDER BY published_at DESC, id DESC`
-	var articles []*model.MinimalFeedArticle
-	err := r.db.SelectContext(ctx, &articles, query)
+// GetRankingCandidates returns up to limit articles matching the filter with the highest For You score, best first.
+// Articles of feeds with a weight of 0 are left out.
+func (r *ArticleRepository) GetRankingCandidates(ctx context.Context, filter model.ArticleFilter, ranking model.RankingQuery, limit int) ([]*model.Article, error) {
+	feedIDs := make([]uuid.UUID, len(ranking.Feeds))
+	weights := make([]float64, len(ranking.Feeds))
+	signals := make([]float64, len(ranking.Feeds))
+	for i, feed := range ranking.Feeds {
+		feedIDs[i], weights[i], signals[i] = feed.FeedID, feed.Weight, feed.Signals
+	}
+
+	var where whereBuilder
+	where.add(notTrashed("articles"))
+	where.addArticleFilter(filter)
+	where.add("COALESCE(s.feed_weight, 1) > 0")
+	feedScores := fmt.Sprintf("unnest(%s::uuid[], %s::float8[], %s::float8[]) AS s(score_feed_id, feed_weight, feed_signals)",
+		where.arg(pq.Array(feedIDs)), where.arg(pq.Array(weights)), where.arg(pq.Array(signals)))
+	// the recency signal halves every half-life since publishing, like in the ranking
+	score := fmt.Sprintf(`COALESCE(s.feed_weight, 1) * (
+		%s * power(0.5, GREATEST(EXTRACT(EPOCH FROM %s::timestamptz - published_at), 0)::float8 / %s)
+		+ COALESCE(s.feed_signals, %s))`,
+		where.arg(ranking.RecencyWeight), where.arg(ranking.Now), where.arg(ranking.RecencyHalfLife.Seconds()),
+		where.arg(ranking.DefaultSignals))
+	query := fmt.Sprintf("SELECT %s FROM articles LEFT JOIN %s ON s.score_feed_id = articles.feed_id %s ORDER BY %s DESC, published_at DESC, id DESC LIMIT %s",
+		selectArticleColumns("articles"), feedScores, where.sql(), score, where.arg(limit))
+
+	var articles []*model.Article
+	err := r.db.SelectContext(ctx, &articles, query, where.args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get saved articles: %w", err)
+		return nil, fmt.Errorf("failed to get ranking candidates: %w", err)
 	}
 	// Ensure empty slice, not nil, if no results
 	if articles == nil {
-		articles = []*model.MinimalFeedArticle{}
+		articles = []*model.Article{}
 	}
 	return articles, nil
 }
 
-func (r *ArticleRepository) IsDuplicate(ctx context.Context, contentHash string) (bool, error) {
-	query := "SELECT COUNT(*) FROM articles WHERE content_hash = $1"
+// Count returns the number of articles matching the filter.
+func (r *ArticleRepository) Count(ctx context.Context, filter model.ArticleFilter) (int, error) {
+	var where whereBuilder
+	where.add(notTrashed("articles"))
+	where.addArticleFilter(filter)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM articles %s", where.sql())
 	var count int
-	err := r.db.GetContext(ctx, &count, query, contentHash)
+	err := r.db.GetContext(ctx, &count, query, where.args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count articles: %w", err)
+	}
+	return count, nil
+}
+
+// Search returns the articles matching the websearch query, best matches first, with highlighted snippets.
+func (r *ArticleRepository) Search(ctx context.Context, searchQuery string, filter model.ArticleFilter, offset, limit int) ([]*model.ArticleSearchResult, error) {
+	var where whereBuilder
+	q := where.addSearch(searchQuery)
+	where.add(notTrashed("articles"))
+	where.addArticleFilter(filter)
+
+	query := fmt.Sprintf(`
+		SELECT %[1]s, ranked.rank,
+		       ts_headline(article_ts_config(a.language),
+		                   regexp_replace(a.description || ' ' || a.content, '<[^>]*>', ' ', 'g'),
+		                   websearch_to_tsquery(article_ts_config(a.language), %[2]s),
+		                   'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10') AS snippet
+		FROM (
+		    SELECT id, published_at, ts_rank_cd(search_vector, websearch_to_tsquery(article_ts_config(language), %[2]s)) AS rank
+		    FROM articles
+		    %[3]s
+		    ORDER BY rank DESC, published_at DESC, id DESC
+		    OFFSET %[4]s LIMIT %[5]s
+		) ranked
+		JOIN articles a ON a.id = ranked.id
+		ORDER BY ranked.rank DESC, ranked.published_at DESC, ranked.id DESC`,
+		selectArticleColumns("a"), q, where.sql(), where.arg(offset), where.arg(limit))
+
+	var results []*model.ArticleSearchResult
+	err := r.db.SelectContext(ctx, &results, query, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if results == nil {
+		results = []*model.ArticleSearchResult{}
+	}
+	return results, nil
+}
+
+// IsDuplicate checks whether an article with the content hash exists or was skipped by a rule. Trashed articles
+// are included, so they are not fetched again.
+func (r *ArticleRepository) IsDuplicate(ctx context.Context, contentHash string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM articles WHERE content_hash = $1)
+		    OR EXISTS (SELECT 1 FROM skipped_articles WHERE content_hash = $1)`
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, contentHash)
 	if err != nil {
 		return false, fmt.Errorf("failed to check for duplicate article: %w", err)
 	}
-	return count > 0, nil
+	return exists, nil
+}
+
+// SaveSkipped stores the content hash of an article skipped by a rule.
+func (r *ArticleRepository) SaveSkipped(ctx context.Context, article *model.Article) error {
+	query := `
+		INSERT INTO skipped_articles (content_hash, feed_id)
+		VALUES ($1, $2)
+		ON CONFLICT (content_hash) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, article.ContentHash, article.FeedID)
+	if err != nil {
+		return fmt.Errorf("failed to save skipped article: %w", err)
+	}
+	return nil
+}
+
+// GetByTitleAndSourceURL returns the articles of the feed with the title and source URL, newest first.
+// Trashed articles are included, like in IsDuplicate.
+func (r *ArticleRepository) GetByTitleAndSourceURL(ctx context.Context, feedID uuid.UUID, title, sourceURL string) ([]*model.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM articles
+		WHERE feed_id = $1 AND title = $2 AND source_url = $3
+		ORDER BY published_at DESC`, selectArticleColumns(""))
+	var articles []*model.Article
+	err := r.db.SelectContext(ctx, &articles, query, feedID, title, sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles by title and source URL: %w", err)
+	}
+	return articles, nil
 }
 
 func (r *ArticleRepository) Save(ctx context.Context, article *model.Article) (*model.Article, error) {
 	query := `
-		INSERT INTO articles (id, title, description, content_hash, source_url, source_type, published_at, last_read_at, save, feed_id)
-		VALUES (:id, :title, :description, :content_hash, :source_url, :source_type, :published_at, :last_read_at, :save, :feed_id)
+		INSERT INTO articles (id, title, description, content, content_hash, source_url, source_type, published_at, last_read_at, save, feed_id, language, word_count, reading_time, simhash, author, categories, priority)
+		VALUES (:id, :title, :description, :content, :content_hash, :source_url, :source_type, :published_at, :last_read_at, :save, :feed_id, :language, :word_count, :reading_time, :simhash, :author, :categories, :priority)
 		ON CONFLICT (id) DO NOTHING
 		RETURNING id`
+	if article.Categories == nil {
+		// a nil array would be stored as NULL
+		article.Categories = pq.StringArray{}
+	}
 	var returnedID uuid.UUID
 	rows, err := r.db.NamedQueryContext(ctx, query, article)
 	if err != nil {
@@ -152,47 +271,167 @@ func (r *ArticleRepository) Save(ctx context.Context, article *model.Article) (*
 	return existing, nil
 }
 
-func (r *ArticleRepository) DeleteOneWeekOldArticles(ctx context.Context) error {
+// UpdateSavedByID saves or unsaves the article. It returns false if the article doesn't exist or it or its feed is in the trash.
+func (r *ArticleRepository) UpdateSavedByID(ctx context.Context, id uuid.UUID, saved bool) (bool, error) {
 	query := `
-		DELETE FROM articles
-		WHERE published_at < NOW() - INTERVAL '1 week'`
-	_, err := r.db.ExecContext(ctx, query)
+		UPDATE articles
+		SET save = $2
+		WHERE id = $1 AND ` + notTrashed("articles")
+	result, err := r.db.ExecContext(ctx, query, id, saved)
 	if err != nil {
-		return fmt.Errorf("failed to delete old articles: %w", err)
+		return false, fmt.Errorf("failed to update saved status for article %s: %w", id, err)
 	}
-	return nil
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for saved status update: %w", err)
+	}
+	return rowsAffected > 0, nil
 }
 
-func (r *ArticleRepository) UpdateSavedByID(ctx context.Context, id uuid.UUID, saved bool) error {
+// UpdateNoteByID sets the note of the article. It returns false if the article doesn't exist.
+func (r *ArticleRepository) UpdateNoteByID(ctx context.Context, id uuid.UUID, note string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE articles SET note = $2 WHERE id = $1 AND "+notTrashed("articles"), id, note)
+	if err != nil {
+		return false, fmt.Errorf("failed to update note for article %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for note update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// UpdateReadByID marks the article as read now or, if read is false, as unread.
+// It returns false if the article doesn't exist or it or its feed is in the trash.
+func (r *ArticleRepository) UpdateReadByID(ctx context.Context, id uuid.UUID, read bool) (bool, error) {
 	query := `
 		UPDATE articles
-		SET save = $2
-		WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id, saved)
+		SET last_read_at = CASE WHEN $2 THEN NOW() END
+		WHERE id = $1 AND ` + notTrashed("articles")
+	result, err := r.db.ExecContext(ctx, query, id, read)
 	if err != nil {
-		return fmt.Errorf("failed to update saved status for article %s: %w", id, err)
+		return false, fmt.Errorf("failed to update read status for article %s: %w", id, err)
 	}
-	return nil
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for read status update: %w", err)
+	}
+	return rowsAffected > 0, nil
 }
 
-func (r *ArticleRepository) UpdateReadByID(ctx context.Context, id uuid.UUID) error {
+// UpdateOpenedByID records that the article was opened now without changing its read state.
+// It returns false if the article doesn't exist or it or its feed is in the trash.
+func (r *ArticleRepository) UpdateOpenedByID(ctx context.Context, id uuid.UUID) (bool, error) {
 	query := `
 		UPDATE articles
-		SET last_read_at = NOW()
-		WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+		SET opened_at = NOW()
+		WHERE id = $1 AND ` + notTrashed("articles")
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update opened time for article %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for opened time update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// AddDwellByID adds the seconds the article was open in the client to its dwell time.
+// It returns false if the article doesn't exist or it or its feed is in the trash.
+func (r *ArticleRepository) AddDwellByID(ctx context.Context, id uuid.UUID, seconds int) (bool, error) {
+	query := `
+		UPDATE articles
+		SET dwell_seconds = dwell_seconds + $2
+		WHERE id = $1 AND ` + notTrashed("articles")
+	result, err := r.db.ExecContext(ctx, query, id, seconds)
+	if err != nil {
+		return false, fmt.Errorf("failed to add dwell time for article %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to update read status for article %s: %w", id, err)
+		return false, fmt.Errorf("failed to get rows affected for dwell time update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// UpdateContent replaces description and content of an article together with the reading statistics and
+// fingerprint derived from them.
+func (r *ArticleRepository) UpdateContent(ctx context.Context, article *model.Article) error {
+	query := `
+		UPDATE articles
+		SET description = :description, content = :content,
+			word_count = :word_count, reading_time = :reading_time, simhash = :simhash
+		WHERE id = :id`
+	_, err := r.db.NamedExecContext(ctx, query, article)
+	if err != nil {
+		return fmt.Errorf("failed to update content for article %s: %w", article.ID, err)
 	}
 	return nil
 }
 
-func (r *ArticleRepository) CountByFeedID(ctx context.Context, feedID uuid.UUID) (int, error) {
-	query := "SELECT COUNT(*) FROM articles WHERE feed_id = $1"
-	var count int
-	err := r.db.GetContext(ctx, &count, query, feedID)
+// Bulk applies the action to the articles with the given IDs and matching the filter in one transaction.
+// Deleted articles are moved to the trash.
+func (r *ArticleRepository) Bulk(ctx context.Context, ids []uuid.UUID, filter model.BulkArticleFilter, action model.BulkArticleAction) (*model.BulkArticleResult, error) {
+	var where whereBuilder
+	where.add(notTrashed("articles"))
+	if len(ids) > 0 {
+		where.add("id = ANY(?)", pq.Array(ids))
+	}
+	if filter.FeedID != nil {
+		where.add("feed_id = ?", *filter.FeedID)
+	}
+	if filter.OlderThan != nil {
+		where.add("published_at < ?", *filter.OlderThan)
+	}
+	if filter.Query != "" {
+		where.addSearch(filter.Query)
+	}
+
+	// statement applies the action, changed selects the articles which are not yet in the target state
+	var statement, changed string
+	switch action {
+	case model.BulkActionRead:
+		statement, changed = "UPDATE articles SET last_read_at = NOW()", "last_read_at IS NULL"
+	case model.BulkActionUnread:
+		statement, changed = "UPDATE articles SET last_read_at = NULL", "last_read_at IS NOT NULL"
+	case model.BulkActionSave:
+		statement, changed = "UPDATE articles SET save = true", "save = false"
+	case model.BulkActionUnsave:
+		statement, changed = "UPDATE articles SET save = false", "save = true"
+	case model.BulkActionDelete:
+		statement = "UPDATE articles SET deleted_at = NOW()"
+	default:
+		return nil, fmt.Errorf("unknown bulk action: %s", action)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count articles for feed %s: %w", feedID, err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return count, nil
+	defer tx.Rollback()
+
+	result := &model.BulkArticleResult{Action: action}
+	err = tx.GetContext(ctx, &result.Matched, "SELECT COUNT(*) FROM articles "+where.sql(), where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count articles of bulk %s: %w", action, err)
+	}
+
+	if changed != "" {
+		where.add(changed)
+	}
+	res, err := tx.ExecContext(ctx, statement+" "+where.sql(), where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply bulk %s: %w", action, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for bulk %s: %w", action, err)
+	}
+	result.Affected = int(affected)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk %s: %w", action, err)
+	}
+	return result, nil
 }
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type CategoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]*model.Category, error) {
+	query := "SELECT * FROM categories ORDER BY position, LOWER(name)"
+	var categories []*model.Category
+	err := r.db.SelectContext(ctx, &categories, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all categories: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if categories == nil {
+		categories = []*model.Category{}
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	err := r.db.GetContext(ctx, &category, "SELECT * FROM categories WHERE id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category by ID: %w", err)
+	}
+	return &category, nil
+}
+
+// IsNameExists checks case-insensitively whether a category other than excludeID has the name.
+func (r *CategoryRepository) IsNameExists(ctx context.Context, name string, excludeID *uuid.UUID) (bool, error) {
+	query := "SELECT COUNT(*) FROM categories WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id != $2)"
+	var count int
+	err := r.db.GetContext(ctx, &count, query, name, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if category name exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+// Create inserts the category after all existing categories.
+func (r *CategoryRepository) Create(ctx context.Context, category *model.Category) (*model.Category, error) {
+	query := `
+		INSERT INTO categories (id, name, position, created_at, updated_at)
+		VALUES (:id, :name, (SELECT COALESCE(MAX(position) + 1, 0) FROM categories), :created_at, :updated_at)
+		RETURNING position`
+	rows, err := r.db.NamedQueryContext(ctx, query, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, fmt.Errorf("failed to create category: no position returned")
+	}
+	if err := rows.Scan(&category.Position); err != nil {
+		return nil, fmt.Errorf("failed to scan returned position: %w", err)
+	}
+	return category, nil
+}
+
+// Rename changes the name of the category. It returns false if the category doesn't exist.
+func (r *CategoryRepository) Rename(ctx context.Context, id uuid.UUID, name string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE categories SET name = $2, updated_at = NOW() WHERE id = $1", id, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to rename category %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for category rename: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete deletes the category, its feeds become uncategorized. It returns false if the category doesn't exist.
+func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete category %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for category deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Reorder sets the position of each category to its index in ids.
+func (r *CategoryRepository) Reorder(ctx context.Context, ids []uuid.UUID) error {
+	query := `
+		UPDATE categories c
+		SET position = o.position - 1, updated_at = NOW()
+		FROM unnest($1::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE c.id = o.id`
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to reorder categories: %w", err)
+	}
+	return nil
+}
+
+// SetFeeds makes the feeds the members of the category in the given order in one transaction.
+// Previous members which are not listed become uncategorized, trashed feeds keep their category.
+// It returns false and changes nothing if one of the feeds doesn't exist or is trashed.
+func (r *CategoryRepository) SetFeeds(ctx context.Context, categoryID uuid.UUID, feedIDs []uuid.UUID) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE feeds
+		SET category_id = NULL, position = 0
+		WHERE category_id = $1 AND NOT (id = ANY($2)) AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, categoryID, pq.Array(feedIDs)); err != nil {
+		return false, fmt.Errorf("failed to remove feeds from category %s: %w", categoryID, err)
+	}
+
+	query = `
+		UPDATE feeds f
+		SET category_id = $1, position = o.position - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE f.id = o.id AND f.deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, categoryID, pq.Array(feedIDs))
+	if err != nil {
+		return false, fmt.Errorf("failed to add feeds to category %s: %w", categoryID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for category feeds: %w", err)
+	}
+	if int(rowsAffected) != len(feedIDs) {
+		return false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit category feeds: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type CollectionRepository struct {
+	db *sqlx.DB
+}
+
+func NewCollectionRepository(db *sqlx.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+// selectCollectionsWithCounts selects the collections together with their number of articles, articles in the
+// trash or of a trashed feed are not counted. The WHERE and ORDER BY clauses are inserted by the caller.
+const selectCollectionsWithCounts = `
+	SELECT c.*, COUNT(ca.article_id) AS article_count
+	FROM collections c
+	LEFT JOIN collection_articles ca ON ca.collection_id = c.id
+		AND EXISTS (
+			SELECT 1 FROM articles d LEFT JOIN feeds df ON df.id = d.feed_id
+			WHERE d.id = ca.article_id AND d.deleted_at IS NULL AND df.deleted_at IS NULL)
+	%s
+	GROUP BY c.id
+	%s`
+
+// GetAll returns all collections, the default collection first and the others by name
+func (r *CollectionRepository) GetAll(ctx context.Context) ([]*model.Collection, error) {
+	query := fmt.Sprintf(selectCollectionsWithCounts, "", "ORDER BY c.is_default DESC, LOWER(c.name)")
+	var collections []*model.Collection
+	err := r.db.SelectContext(ctx, &collections, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all collections: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if collections == nil {
+		collections = []*model.Collection{}
+	}
+	return collections, nil
+}
+
+func (r *CollectionRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Collection, error) {
+	query := fmt.Sprintf(selectCollectionsWithCounts, "WHERE c.id = $1", "")
+	var collection model.Collection
+	err := r.db.GetContext(ctx, &collection, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection by ID: %w", err)
+	}
+	return &collection, nil
+}
+
+// GetByArticleID returns the collections containing the article
+func (r *CollectionRepository) GetByArticleID(ctx context.Context, articleID uuid.UUID) ([]*model.Collection, error) {
+	where := "WHERE c.id IN (SELECT collection_id FROM collection_articles WHERE article_id = $1)"
+	query := fmt.Sprintf(selectCollectionsWithCounts, where, "ORDER BY c.is_default DESC, LOWER(c.name)")
+	var collections []*model.Collection
+	err := r.db.SelectContext(ctx, &collections, query, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections of article %s: %w", articleID, err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if collections == nil {
+		collections = []*model.Collection{}
+	}
+	return collections, nil
+}
+
+// IsNameExists checks case-insensitively whether a collection other than excludeID has the name.
+func (r *CollectionRepository) IsNameExists(ctx context.Context, name string, excludeID *uuid.UUID) (bool, error) {
+	query := "SELECT COUNT(*) FROM collections WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id != $2)"
+	var count int
+	err := r.db.GetContext(ctx, &count, query, name, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if collection name exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *CollectionRepository) Create(ctx context.Context, collection *model.Collection) (*model.Collection, error) {
+	query := `
+		INSERT INTO collections (id, name, description, created_at, updated_at)
+		VALUES (:id, :name, :description, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return collection, nil
+}
+
+// Update changes name and description of the collection. It returns false if the collection doesn't exist.
+func (r *CollectionRepository) Update(ctx context.Context, id uuid.UUID, name, description string) (bool, error) {
+	query := "UPDATE collections SET name = $2, description = $3, updated_at = NOW() WHERE id = $1"
+	result, err := r.db.ExecContext(ctx, query, id, name, description)
+	if err != nil {
+		return false, fmt.Errorf("failed to update collection %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for collection update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete deletes the collection, its articles are kept. It returns false if the collection doesn't exist.
+func (r *CollectionRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM collections WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete collection %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for collection deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetArticles returns up to limit articles of the collection in their order, starting after the given
+// position and article ID if after is not nil.
+func (r *CollectionRepository) GetArticles(ctx context.Context, collectionID uuid.UUID, after *model.CollectionArticle, limit int) ([]*model.CollectionArticle, error) {
+	var where whereBuilder
+	where.add("ca.collection_id = ?", collectionID)
+	where.add(notTrashed("a"))
+	if after != nil {
+		where.add("(ca.position, ca.article_id) > (?, ?)", after.Position, after.ID)
+	}
+	query := fmt.Sprintf(`
+		SELECT %s, ca.position AS collection_position, ca.added_at AS collection_added_at
+		FROM collection_articles ca
+		JOIN articles a ON a.id = ca.article_id
+		%s
+		ORDER BY ca.position, ca.article_id
+		LIMIT %s`, selectArticleColumns("a"), where.sql(), where.arg(limit))
+
+	var articles []*model.CollectionArticle
+	err := r.db.SelectContext(ctx, &articles, query, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles of collection %s: %w", collectionID, err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if articles == nil {
+		articles = []*model.CollectionArticle{}
+	}
+	return articles, nil
+}
+
+// AddArticle puts the article at the position of the collection, or at the end if position is nil.
+// An article already in the collection is moved.
+func (r *CollectionRepository) AddArticle(ctx context.Context, collectionID, articleID uuid.UUID, position *int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertCollectionArticle(ctx, tx, collectionID, articleID, position); err != nil {
+		return err
+	}
+	if err := syncSaved(ctx, tx, articleID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit collection article: %w", err)
+	}
+	return nil
+}
+
+// RemoveArticle removes the article from the collection. It returns false if the article isn't in the collection.
+func (r *CollectionRepository) RemoveArticle(ctx context.Context, collectionID, articleID uuid.UUID) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	found, err := deleteCollectionArticle(ctx, tx, collectionID, articleID)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := syncSaved(ctx, tx, articleID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit collection article removal: %w", err)
+	}
+	return true, nil
+}
+
+// MoveArticle moves the article from the source collection to the position of the target collection,
+// which may be the same. It returns false if the article isn't in the source collection.
+func (r *CollectionRepository) MoveArticle(ctx context.Context, sourceID, articleID, targetID uuid.UUID, position *int) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	found, err := deleteCollectionArticle(ctx, tx, sourceID, articleID)
+	if err != nil || !found {
+		return false, err
+	}
+	if err := insertCollectionArticle(ctx, tx, targetID, articleID, position); err != nil {
+		return false, err
+	}
+	if err := syncSaved(ctx, tx, articleID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit collection article move: %w", err)
+	}
+	return true, nil
+}
+
+// insertCollectionArticle inserts or moves the article to the index of the collection and renumbers the
+// positions of the collection
+func insertCollectionArticle(ctx context.Context, tx *sqlx.Tx, collectionID, articleID uuid.UUID, position *int) error {
+	var ids []uuid.UUID
+	query := `
+		SELECT article_id FROM collection_articles
+		WHERE collection_id = $1 AND article_id != $2
+		ORDER BY position, article_id
+		FOR UPDATE`
+	if err := tx.SelectContext(ctx, &ids, query, collectionID, articleID); err != nil {
+		return fmt.Errorf("failed to get order of collection %s: %w", collectionID, err)
+	}
+
+	index := len(ids)
+	if position != nil && *position < index {
+		index = max(*position, 0)
+	}
+	ids = slices.Insert(ids, index, articleID)
+
+	query = `
+		INSERT INTO collection_articles (collection_id, article_id, position)
+		SELECT $1, o.id, o.position - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		ON CONFLICT (collection_id, article_id) DO UPDATE SET position = EXCLUDED.position`
+	if _, err := tx.ExecContext(ctx, query, collectionID, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to add article %s to collection %s: %w", articleID, collectionID, err)
+	}
+	return nil
+}
+
+func deleteCollectionArticle(ctx context.Context, tx *sqlx.Tx, collectionID, articleID uuid.UUID) (bool, error) {
+	query := "DELETE FROM collection_articles WHERE collection_id = $1 AND article_id = $2"
+	result, err := tx.ExecContext(ctx, query, collectionID, articleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove article %s from collection %s: %w", articleID, collectionID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for collection article removal: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// syncSaved sets the save flag of the article to its membership in the default collection.
+// The other direction is kept in sync by a trigger on articles.save.
+func syncSaved(ctx context.Context, tx *sqlx.Tx, articleID uuid.UUID) error {
+	query := `
+		UPDATE articles a
+		SET save = s.saved
+		FROM (
+			SELECT EXISTS (
+				SELECT 1 FROM collection_articles ca
+				JOIN collections c ON c.id = ca.collection_id
+				WHERE c.is_default AND ca.article_id = $1
+			) AS saved
+		) s
+		WHERE a.id = $1 AND a.save != s.saved`
+	if _, err := tx.ExecContext(ctx, query, articleID); err != nil {
+		return fmt.Errorf("failed to update saved status for article %s: %w", articleID, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code of a violated unique constraint
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether the statement failed because of a unique constraint, for example a
+// concurrent insert of the same name after the service checked it was free
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	wrapped := fmt.Errorf("failed to create tag: %w", &pq.Error{Code: "23505"})
+	if !IsUniqueViolation(wrapped) {
+		t.Error("Expected a wrapped unique violation to be detected")
+	}
+	if IsUniqueViolation(&pq.Error{Code: "23503"}) || IsUniqueViolation(errors.New("23505")) || IsUniqueViolation(nil) {
+		t.Error("Expected other errors not to be unique violations")
+	}
+}
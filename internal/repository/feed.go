@@ -7,23 +7,36 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/lucasg04/fyrss-server/internal/model"
 )
 
 type FeedRepository struct {
-	db          *sqlx.DB
-	articleRepo *ArticleRepository
+	db *sqlx.DB
 }
 
 func NewFeedRepository(db *sqlx.DB) *FeedRepository {
-	return &FeedRepository{
-		db:          db,
-		articleRepo: NewArticleRepository(db),
-	}
+	return &FeedRepository{db: db}
 }
 
+// selectFeedsWithCounts selects the feeds together with their article counts in one aggregate query.
+// Trashed articles are not counted. The WHERE and ORDER BY clauses are inserted by the caller.
+const selectFeedsWithCounts = `
+	SELECT f.*,
+	       COUNT(a.id) AS article_count,
+	       COUNT(a.id) FILTER (WHERE a.last_read_at IS NULL) AS unread_count,
+	       COUNT(a.id) FILTER (WHERE a.published_at > f.last_read_at) AS new_since_last_visit
+	FROM feeds f
+	LEFT JOIN articles a ON a.feed_id = f.id AND a.deleted_at IS NULL
+	%s
+	GROUP BY f.id
+	%s`
+
 func (r *FeedRepository) GetAll(ctx context.Context) ([]*model.Feed, error) {
-	query := "SELECT * FROM feeds ORDER BY created_at DESC"
+	// categories in their order, uncategorized feeds last
+	query := fmt.Sprintf(selectFeedsWithCounts, "WHERE f.deleted_at IS NULL", `
+		ORDER BY (SELECT c.position FROM categories c WHERE c.id = f.category_id) NULLS LAST,
+		         f.position, f.created_at DESC`)
 	var feeds []*model.Feed
 	err := r.db.SelectContext(ctx, &feeds, query)
 	if err != nil {
@@ -33,41 +46,23 @@ func (r *FeedRepository) GetAll(ctx context.Context) ([]*model.Feed, error) {
 	if feeds == nil {
 		feeds = []*model.Feed{}
 	}
-
-	// Populate article count for each feed
-	for _, feed := range feeds {
-		count, err := r.articleRepo.CountByFeedID(ctx, feed.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get article count for feed %s: %w", feed.ID, err)
-		}
-		feed.ArticleCount = count
-	}
-
 	return feeds, nil
 }
 
 func (r *FeedRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Feed, error) {
-	query := "SELECT * FROM feeds WHERE id = $1"
+	query := fmt.Sprintf(selectFeedsWithCounts, "WHERE f.id = $1 AND f.deleted_at IS NULL", "")
 	var feed model.Feed
 	err := r.db.GetContext(ctx, &feed, query, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed by ID: %w", err)
 	}
-
-	// Populate article count
-	count, err := r.articleRepo.CountByFeedID(ctx, feed.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get article count for feed %s: %w", feed.ID, err)
-	}
-	feed.ArticleCount = count
-
 	return &feed, nil
 }
 
 func (r *FeedRepository) Create(ctx context.Context, feed *model.Feed) (*model.Feed, error) {
 	query := `
-		INSERT INTO feeds (id, name, url, created_at, updated_at, last_read_at)
-		VALUES (:id, :name, :url, :created_at, :updated_at, :last_read_at)
+		INSERT INTO feeds (id, name, url, source_kind, source_config, weight, created_at, updated_at, last_read_at)
+		VALUES (:id, :name, :url, :source_kind, :source_config, :weight, :created_at, :updated_at, :last_read_at)
 		RETURNING id`
 	var returnedID uuid.UUID
 	rows, err := r.db.NamedQueryContext(ctx, query, feed)
@@ -90,27 +85,40 @@ func (r *FeedRepository) Create(ctx context.Context, feed *model.Feed) (*model.F
 func (r *FeedRepository) Update(ctx context.Context, id uuid.UUID, feed *model.Feed) (*model.Feed, error) {
 	query := `
 		UPDATE feeds
-		SET name = $2, url = $3, updated_at = NOW()
-		WHERE id = $1
-		RETURNING id, name, url, created_at, updated_at, last_read_at`
-	var updatedFeed model.Feed
-	err := r.db.GetContext(ctx, &updatedFeed, query, id, feed.Name, feed.URL)
+		SET name = $2, url = $3, source_kind = $4, source_config = $5, weight = $6, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id`
+	var updatedID uuid.UUID
+	err := r.db.GetContext(ctx, &updatedID, query, id, feed.Name, feed.URL, feed.SourceKind, feed.SourceConfig, feed.Weight)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update feed with ID %s: %w", id, err)
 	}
 
-	// Populate article count
-	count, err := r.articleRepo.CountByFeedID(ctx, updatedFeed.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get article count for updated feed %s: %w", updatedFeed.ID, err)
+	// Reload the feed to populate the article counts
+	return r.GetByID(ctx, updatedID)
+}
+
+// GetArticleFeedsByIDs returns the name and icon of the given feeds by feed ID
+func (r *FeedRepository) GetArticleFeedsByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*model.ArticleFeed, error) {
+	feedsByID := make(map[uuid.UUID]*model.ArticleFeed, len(ids))
+	if len(ids) == 0 {
+		return feedsByID, nil
 	}
-	updatedFeed.ArticleCount = count
 
-	return &updatedFeed, nil
+	var feeds []*model.ArticleFeed
+	err := r.db.SelectContext(ctx, &feeds, "SELECT id, name, icon_url FROM feeds WHERE id = ANY($1) AND deleted_at IS NULL", pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeds by IDs: %w", err)
+	}
+	for _, feed := range feeds {
+		feedsByID[feed.ID] = feed
+	}
+	return feedsByID, nil
 }
 
+// Delete moves the feed to the trash, its articles are kept
 func (r *FeedRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := "DELETE FROM feeds WHERE id = $1"
+	query := "UPDATE feeds SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete feed with ID %s: %w", id, err)
@@ -129,7 +137,7 @@ func (r *FeedRepository) UpdateLastReadAt(ctx context.Context, id uuid.UUID, las
 	query := `
 		UPDATE feeds
 		SET last_read_at = $2
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 	result, err := r.db.ExecContext(ctx, query, id, lastReadAt)
 	if err != nil {
 		return fmt.Errorf("failed to update last read at for feed %s: %w", id, err)
@@ -144,6 +152,16 @@ func (r *FeedRepository) UpdateLastReadAt(ctx context.Context, id uuid.UUID, las
 	return nil
 }
 
+func (r *FeedRepository) UpdateIconURL(ctx context.Context, id uuid.UUID, iconURL string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE feeds SET icon_url = $2 WHERE id = $1", id, iconURL)
+	if err != nil {
+		return fmt.Errorf("failed to update icon of feed %s: %w", id, err)
+	}
+	return nil
+}
+
+// IsURLExists checks whether a feed other than excludeID has the URL. Trashed feeds keep their URL until
+// they are purged.
 func (r *FeedRepository) IsURLExists(ctx context.Context, url string, excludeID *uuid.UUID) (bool, error) {
 	var query string
 	var args []interface{}
@@ -163,3 +181,98 @@ func (r *FeedRepository) IsURLExists(ctx context.Context, url string, excludeID
 	}
 	return count > 0, nil
 }
+
+// GetHealth returns the fetch results of the feeds with their number of articles published since recentSince
+// and their newest publish date. Trashed articles are not counted.
+func (r *FeedRepository) GetHealth(ctx context.Context, recentSince time.Time) ([]*model.FeedHealth, error) {
+	query := `
+		SELECT f.id AS feed_id, f.name, f.url, f.created_at,
+		       h.last_success_at, h.last_failure_at,
+		       COALESCE(h.last_error, '') AS last_error,
+		       COALESCE(h.consecutive_failures, 0) AS consecutive_failures,
+		       h.last_validated_at,
+		       COALESCE(h.validation_error, '') AS validation_error,
+		       (SELECT COUNT(*) FROM articles a
+		        WHERE a.feed_id = f.id AND a.published_at >= $1 AND a.deleted_at IS NULL) AS recent_count,
+		       (SELECT MAX(a.published_at) FROM articles a
+		        WHERE a.feed_id = f.id AND a.deleted_at IS NULL) AS last_published_at
+		FROM feeds f
+		LEFT JOIN feed_health h ON h.feed_id = f.id
+		WHERE f.deleted_at IS NULL
+		ORDER BY LOWER(f.name), f.id`
+	var health []*model.FeedHealth
+	err := r.db.SelectContext(ctx, &health, query, recentSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed health: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if health == nil {
+		health = []*model.FeedHealth{}
+	}
+	return health, nil
+}
+
+// RecordFetchSuccess stores a successful fetch of the feed and resets its consecutive failures
+func (r *FeedRepository) RecordFetchSuccess(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `
+		INSERT INTO feed_health (feed_id, last_success_at)
+		VALUES ($1, $2)
+		ON CONFLICT (feed_id) DO UPDATE
+		SET last_success_at = EXCLUDED.last_success_at, consecutive_failures = 0`
+	_, err := r.db.ExecContext(ctx, query, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to record fetch of feed %s: %w", id, err)
+	}
+	return nil
+}
+
+// RecordFetchFailure stores a failed fetch of the feed and counts its consecutive failures
+func (r *FeedRepository) RecordFetchFailure(ctx context.Context, id uuid.UUID, at time.Time, fetchErr string) error {
+	query := `
+		INSERT INTO feed_health (feed_id, last_failure_at, last_error, consecutive_failures)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (feed_id) DO UPDATE
+		SET last_failure_at = EXCLUDED.last_failure_at, last_error = EXCLUDED.last_error,
+		    consecutive_failures = feed_health.consecutive_failures + 1`
+	_, err := r.db.ExecContext(ctx, query, id, at, fetchErr)
+	if err != nil {
+		return fmt.Errorf("failed to record failed fetch of feed %s: %w", id, err)
+	}
+	return nil
+}
+
+// RecordValidation stores the result of a validation of the feed, validationErr is empty if it succeeded
+func (r *FeedRepository) RecordValidation(ctx context.Context, id uuid.UUID, at time.Time, validationErr string) error {
+	query := `
+		INSERT INTO feed_health (feed_id, last_validated_at, validation_error)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (feed_id) DO UPDATE
+		SET last_validated_at = EXCLUDED.last_validated_at, validation_error = EXCLUDED.validation_error`
+	_, err := r.db.ExecContext(ctx, query, id, at, validationErr)
+	if err != nil {
+		return fmt.Errorf("failed to record validation of feed %s: %w", id, err)
+	}
+	return nil
+}
+
+// GetEngagement returns the reading history of each feed, which the For You ranking learns from.
+func (r *FeedRepository) GetEngagement(ctx context.Context) ([]*model.FeedEngagement, error) {
+	query := `
+		SELECT f.id AS feed_id, f.weight,
+		       COUNT(a.id) AS article_count,
+		       COUNT(a.id) FILTER (WHERE a.last_read_at IS NOT NULL) AS read_count,
+		       COUNT(a.id) FILTER (WHERE a.save) AS saved_count,
+		       COUNT(a.id) FILTER (WHERE a.dwell_seconds > 0) AS dwell_count,
+		       COALESCE(AVG(LEAST(a.dwell_seconds / GREATEST(a.reading_time * 60.0, 30.0), 1.0))
+		                FILTER (WHERE a.dwell_seconds > 0), 0) AS dwell_ratio
+		FROM feeds f
+		LEFT JOIN articles a ON a.feed_id = f.id AND a.deleted_at IS NULL
+		WHERE f.deleted_at IS NULL
+		GROUP BY f.id`
+	var engagement []*model.FeedEngagement
+	err := r.db.SelectContext(ctx, &engagement, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed engagement: %w", err)
+	}
+	return engagement, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type HighlightRepository struct {
+	db *sqlx.DB
+}
+
+func NewHighlightRepository(db *sqlx.DB) *HighlightRepository {
+	return &HighlightRepository{db: db}
+}
+
+// highlightOrder orders highlights by their position in the article text
+const highlightOrder = "ORDER BY (anchor->>'start')::int, created_at, id"
+
+func (r *HighlightRepository) GetByArticleID(ctx context.Context, articleID uuid.UUID) ([]*model.Highlight, error) {
+	query := "SELECT * FROM highlights WHERE article_id = $1 " + highlightOrder
+	var highlights []*model.Highlight
+	err := r.db.SelectContext(ctx, &highlights, query, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights of article %s: %w", articleID, err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if highlights == nil {
+		highlights = []*model.Highlight{}
+	}
+	return highlights, nil
+}
+
+func (r *HighlightRepository) GetByID(ctx context.Context, articleID, id uuid.UUID) (*model.Highlight, error) {
+	var highlight model.Highlight
+	err := r.db.GetContext(ctx, &highlight, "SELECT * FROM highlights WHERE id = $1 AND article_id = $2", id, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlight by ID: %w", err)
+	}
+	return &highlight, nil
+}
+
+// GetAnnotatedArticleIDs returns the IDs of all articles with a note or highlights
+func (r *HighlightRepository) GetAnnotatedArticleIDs(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM articles WHERE note <> ''
+		UNION
+		SELECT article_id FROM highlights`
+	var ids []uuid.UUID
+	err := r.db.SelectContext(ctx, &ids, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotated articles: %w", err)
+	}
+	return ids, nil
+}
+
+// GetAll returns all highlights grouped by article ID
+func (r *HighlightRepository) GetAll(ctx context.Context) (map[uuid.UUID][]*model.Highlight, error) {
+	query := "SELECT * FROM highlights " + highlightOrder
+	var highlights []*model.Highlight
+	err := r.db.SelectContext(ctx, &highlights, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all highlights: %w", err)
+	}
+	byArticle := make(map[uuid.UUID][]*model.Highlight)
+	for _, highlight := range highlights {
+		byArticle[highlight.ArticleID] = append(byArticle[highlight.ArticleID], highlight)
+	}
+	return byArticle, nil
+}
+
+func (r *HighlightRepository) Create(ctx context.Context, highlight *model.Highlight) (*model.Highlight, error) {
+	query := `
+		INSERT INTO highlights (id, article_id, quote, anchor, color, note, created_at, updated_at)
+		VALUES (:id, :article_id, :quote, :anchor, :color, :note, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, highlight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create highlight: %w", err)
+	}
+	return highlight, nil
+}
+
+// Update changes the color and note of the highlight. It returns false if the article has no such highlight.
+func (r *HighlightRepository) Update(ctx context.Context, articleID, id uuid.UUID, color model.HighlightColor, note string) (bool, error) {
+	query := `
+		UPDATE highlights
+		SET color = $3, note = $4, updated_at = NOW()
+		WHERE id = $1 AND article_id = $2`
+	result, err := r.db.ExecContext(ctx, query, id, articleID, color, note)
+	if err != nil {
+		return false, fmt.Errorf("failed to update highlight %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for highlight update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete deletes the highlight. It returns false if the article has no such highlight.
+func (r *HighlightRepository) Delete(ctx context.Context, articleID, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM highlights WHERE id = $1 AND article_id = $2", id, articleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete highlight %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for highlight deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
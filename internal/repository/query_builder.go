@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+// whereBuilder collects SQL conditions with numbered postgres placeholders.
+type whereBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// add appends a condition. Each ? in the condition is replaced with the placeholder of the next value.
+func (b *whereBuilder) add(condition string, values ...any) {
+	for _, value := range values {
+		b.args = append(b.args, value)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+	b.conditions = append(b.conditions, condition)
+}
+
+// arg adds a value which is referenced outside of the conditions and returns its placeholder.
+func (b *whereBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// sql returns the conditions joined with AND, prefixed with WHERE, or an empty string if there are none.
+func (b *whereBuilder) sql() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// notTrashed is the condition excluding trashed articles and the articles of trashed feeds, for the articles
+// table or its alias. Restoring a feed makes its articles visible again.
+func notTrashed(table string) string {
+	return fmt.Sprintf("%[1]s.deleted_at IS NULL AND NOT EXISTS "+
+		"(SELECT 1 FROM feeds tf WHERE tf.id = %[1]s.feed_id AND tf.deleted_at IS NOT NULL)", table)
+}
+
+// searchConfigs are the text search configurations of all supported article languages, see article_ts_config
+var searchConfigs = []string{"german", "english", "french", "spanish", "italian", "dutch", "simple"}
+
+// addSearch adds the conditions matching the websearch query and returns the placeholder of the query.
+func (b *whereBuilder) addSearch(searchQuery string) string {
+	q := b.arg(searchQuery)
+
+	// The query is parsed with every configuration so the GIN index can be used,
+	// then matches are checked again with the configuration of the article language.
+	configQueries := make([]string, len(searchConfigs))
+	for i, config := range searchConfigs {
+		configQueries[i] = fmt.Sprintf("websearch_to_tsquery('%s', %s)", config, q)
+	}
+	b.add(fmt.Sprintf("search_vector @@ (%s)", strings.Join(configQueries, " || ")))
+	b.add(fmt.Sprintf("search_vector @@ websearch_to_tsquery(article_ts_config(language), %s)", q))
+	return q
+}
+
+// addArticleFilter adds the conditions of the article filter.
+func (b *whereBuilder) addArticleFilter(filter model.ArticleFilter) {
+	if len(filter.FeedIDs) > 0 {
+		b.add("feed_id = ANY(?)", pq.Array(filter.FeedIDs))
+	}
+	if len(filter.TagIDs) > 0 {
+		b.add("EXISTS (SELECT 1 FROM article_tags t WHERE t.article_id = articles.id AND t.tag_id = ANY(?))", pq.Array(filter.TagIDs))
+	}
+	if filter.Language != "" {
+		b.add("language = ?", filter.Language)
+	}
+	if filter.SourceType != "" {
+		b.add("source_type = ?", filter.SourceType)
+	}
+	if filter.TitleContains != "" {
+		b.add(`title ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.TitleContains)+"%")
+	}
+	if filter.Saved != nil {
+		b.add("save = ?", *filter.Saved)
+	}
+	if filter.Read != nil {
+		if *filter.Read {
+			b.add("last_read_at IS NOT NULL")
+		} else {
+			b.add("last_read_at IS NULL")
+		}
+	}
+	if filter.PublishedFrom != nil {
+		b.add("published_at >= ?", *filter.PublishedFrom)
+	}
+	if filter.PublishedTo != nil {
+		b.add("published_at <= ?", *filter.PublishedTo)
+	}
+	if filter.PublishedAfter != nil {
+		b.add("published_at > ?", *filter.PublishedAfter)
+	}
+	if filter.ReadFrom != nil {
+		b.add("last_read_at >= ?", *filter.ReadFrom)
+	}
+	if filter.ReadTo != nil {
+		b.add("last_read_at <= ?", *filter.ReadTo)
+	}
+	if filter.MinReadingTime != nil {
+		b.add("reading_time >= ?", *filter.MinReadingTime)
+	}
+	if filter.MaxReadingTime != nil {
+		b.add("reading_time <= ?", *filter.MaxReadingTime)
+	}
+	if filter.CollapseClusters {
+		// hide articles of a cluster unless they are its representative or it is trashed
+		b.add(`NOT EXISTS (
+			SELECT 1 FROM story_clusters c
+			JOIN articles r ON r.id = c.representative_article_id
+			WHERE c.id = articles.story_cluster_id AND r.id != articles.id AND ` + notTrashed("r") + `)`)
+	}
+}
+
+// sortColumn is a column of an article order
+type sortColumn struct {
+	name string
+	desc bool
+}
+
+// articleSortColumns are the columns of each article sort. Every order ends with the id so it is total,
+// which keyset pagination relies on.
+var articleSortColumns = map[model.ArticleSort][]sortColumn{
+	model.ArticleSortNewest:       {{"published_at", true}, {"id", true}},
+	model.ArticleSortOldest:       {{"published_at", false}, {"id", false}},
+	model.ArticleSortShortest:     {{"reading_time", false}, {"published_at", true}, {"id", true}},
+	model.ArticleSortLongest:      {{"reading_time", true}, {"published_at", true}, {"id", true}},
+	model.ArticleSortRecentlyRead: {{"last_read_at", true}, {"id", true}},
+	model.ArticleSortPriority:     {{"priority", true}, {"published_at", true}, {"id", true}},
+}
+
+// articleSort returns the columns of the sort, or of defaultSort for an empty or unknown sort.
+func articleSort(sort, defaultSort model.ArticleSort) []sortColumn {
+	if columns, ok := articleSortColumns[sort]; ok {
+		return columns
+	}
+	return articleSortColumns[defaultSort]
+}
+
+// orderBy returns the ORDER BY expression of the columns.
+func orderBy(columns []sortColumn) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = column.name + " ASC"
+		if column.desc {
+			parts[i] = column.name + " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// articleOrderBy returns the ORDER BY expression for the sort, or of defaultSort for an empty sort.
+func articleOrderBy(sort, defaultSort model.ArticleSort) string {
+	return orderBy(articleSort(sort, defaultSort))
+}
+
+// addKeyset adds the condition selecting the rows after the cursor in the order of the columns.
+func (b *whereBuilder) addKeyset(columns []sortColumn, cursor model.ArticleCursor) {
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		values[i] = articleCursorValue(cursor, column.name)
+	}
+
+	uniform := true
+	for _, column := range columns {
+		uniform = uniform && column.desc == columns[0].desc
+	}
+	if uniform {
+		// a row comparison can use a multicolumn index
+		names := make([]string, len(columns))
+		placeholders := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.name
+			placeholders[i] = "?"
+		}
+		b.add(fmt.Sprintf("(%s) %s (%s)", strings.Join(names, ", "), keysetOperator(columns[0]), strings.Join(placeholders, ", ")), values...)
+		return
+	}
+
+	// mixed directions: (a > ?) OR (a = ? AND b < ?) OR ...
+	var alternatives []string
+	var args []any
+	for i, column := range columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, columns[j].name+" = ?")
+			args = append(args, values[j])
+		}
+		parts = append(parts, fmt.Sprintf("%s %s ?", column.name, keysetOperator(column)))
+		args = append(args, values[i])
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+	b.add("("+strings.Join(alternatives, " OR ")+")", args...)
+}
+
+func keysetOperator(column sortColumn) string {
+	if column.desc {
+		return "<"
+	}
+	return ">"
+}
+
+// articleCursorValue returns the value of the cursor for a sort column.
+func articleCursorValue(cursor model.ArticleCursor, column string) any {
+	switch column {
+	case "published_at":
+		return cursor.PublishedAt
+	case "last_read_at":
+		return cursor.LastReadAt
+	case "reading_time":
+		return cursor.ReadingTime
+	case "priority":
+		return cursor.Priority
+	default:
+		return cursor.ID
+	}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
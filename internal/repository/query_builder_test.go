@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestWhereBuilder_ArticleFilter(t *testing.T) {
+	minReadingTime, maxReadingTime := 2, 10
+	var where whereBuilder
+	where.add("save = true")
+	where.add("feed_id = ?", "feed")
+	where.addArticleFilter(model.ArticleFilter{
+		Language:       "de",
+		MinReadingTime: &minReadingTime,
+		MaxReadingTime: &maxReadingTime,
+	})
+
+	want := "WHERE save = true AND feed_id = $1 AND language = $2 AND reading_time >= $3 AND reading_time <= $4"
+	if got := where.sql(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if len(where.args) != 4 {
+		t.Errorf("Expected 4 args, got %d", len(where.args))
+	}
+}
+
+func TestWhereBuilder_ReadAndTitleFilter(t *testing.T) {
+	read := true
+	var where whereBuilder
+	where.addArticleFilter(model.ArticleFilter{Read: &read, TitleContains: "100%_sure"})
+
+	want := `WHERE title ILIKE $1 ESCAPE '\' AND last_read_at IS NOT NULL`
+	if got := where.sql(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := where.args[0]; got != `%100\%\_sure%` {
+		t.Errorf("Expected escaped title pattern, got %q", got)
+	}
+}
+
+func TestWhereBuilder_TagFilter(t *testing.T) {
+	var where whereBuilder
+	where.addArticleFilter(model.ArticleFilter{TagIDs: []uuid.UUID{uuid.New(), uuid.New()}})
+
+	want := "WHERE EXISTS (SELECT 1 FROM article_tags t WHERE t.article_id = articles.id AND t.tag_id = ANY($1))"
+	if got := where.sql(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestWhereBuilder_Empty(t *testing.T) {
+	var where whereBuilder
+	if got := where.sql(); got != "" {
+		t.Errorf("Expected empty clause, got %q", got)
+	}
+}
+
+func TestWhereBuilder_KeysetUniformOrder(t *testing.T) {
+	cursor := model.ArticleCursor{ID: uuid.New(), PublishedAt: time.Now()}
+	var where whereBuilder
+	where.add("save = true")
+	where.addKeyset(articleSort(model.ArticleSortNewest, ""), cursor)
+
+	want := "WHERE save = true AND (published_at, id) < ($1, $2)"
+	if got := where.sql(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if where.args[0] != cursor.PublishedAt || where.args[1] != cursor.ID {
+		t.Errorf("Expected cursor values as args, got %v", where.args)
+	}
+}
+
+func TestWhereBuilder_KeysetMixedOrder(t *testing.T) {
+	cursor := model.ArticleCursor{ID: uuid.New(), PublishedAt: time.Now(), ReadingTime: 4}
+	var where whereBuilder
+	where.addKeyset(articleSort(model.ArticleSortShortest, ""), cursor)
+
+	want := "WHERE ((reading_time > $1) OR (reading_time = $2 AND published_at < $3) OR " +
+		"(reading_time = $4 AND published_at = $5 AND id < $6))"
+	if got := where.sql(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if len(where.args) != 6 {
+		t.Errorf("Expected 6 args, got %d", len(where.args))
+	}
+}
+
+func TestArticleOrderBy_Default(t *testing.T) {
+	if got := articleOrderBy("", model.ArticleSortRecentlyRead); got != "last_read_at DESC, id DESC" {
+		t.Errorf("Expected the default order, got %q", got)
+	}
+	if got := articleOrderBy(model.ArticleSortLongest, model.ArticleSortNewest); got != "reading_time DESC, published_at DESC, id DESC" {
+		t.Errorf("Expected the longest order, got %q", got)
+	}
+}
+
+func TestArticleColumnsMatchModel(t *testing.T) {
+	articleType := reflect.TypeOf(model.Article{})
+	var tags []string
+	for i := 0; i < articleType.NumField(); i++ {
+		if tag := articleType.Field(i).Tag.Get("db"); tag != "" && tag != "-" {
+			tags = append(tags, tag)
+		}
+	}
+
+	if !reflect.DeepEqual(tags, articleColumns) {
+		t.Errorf("articleColumns %v don't match db tags of model.Article %v", articleColumns, tags)
+	}
+}
+
+func TestSelectArticleColumns_WithAlias(t *testing.T) {
+	got := selectArticleColumns("a")
+	if !strings.HasPrefix(got, "a.id, a.title, ") || strings.Contains(got, ", id") {
+		t.Errorf("Expected all columns to be qualified, got %q", got)
+	}
+}
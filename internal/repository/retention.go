@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type RetentionRepository struct {
+	db *sqlx.DB
+}
+
+func NewRetentionRepository(db *sqlx.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// selectExpiredArticleIDs selects the articles the retention policies move to the trash. Every article uses the
+// policy of its feed or the global policy. The newest articles of a feed are ranked first for the keep latest setting.
+const selectExpiredArticleIDs = `
+	SELECT a.id
+	FROM (
+		SELECT id, feed_id, published_at, last_read_at, save, note,
+		       ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY published_at DESC, id DESC) AS feed_rank
+		FROM articles
+		WHERE deleted_at IS NULL
+	) a
+	JOIN LATERAL (
+		SELECT * FROM retention_policies p
+		WHERE p.feed_id = a.feed_id OR p.feed_id IS NULL
+		ORDER BY p.feed_id NULLS LAST
+		LIMIT 1
+	) p ON true
+	WHERE p.max_age_days IS NOT NULL
+	  AND a.published_at < NOW() - make_interval(days => CASE
+	      WHEN a.last_read_at IS NULL THEN COALESCE(p.unread_max_age_days, p.max_age_days)
+	      ELSE p.max_age_days END)
+	  AND a.feed_rank > COALESCE(p.keep_latest, 0)
+	  AND NOT a.save
+	  AND a.note = ''
+	  AND NOT EXISTS (SELECT 1 FROM article_tags t WHERE t.article_id = a.id)
+	  AND NOT EXISTS (SELECT 1 FROM highlights h WHERE h.article_id = a.id)
+	  AND NOT EXISTS (SELECT 1 FROM collection_articles ca WHERE ca.article_id = a.id)`
+
+// GetAll returns the global policy first and then the policies of the feeds
+func (r *RetentionRepository) GetAll(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	query := "SELECT * FROM retention_policies ORDER BY feed_id NULLS FIRST, created_at"
+	var policies []*model.RetentionPolicy
+	err := r.db.SelectContext(ctx, &policies, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if policies == nil {
+		policies = []*model.RetentionPolicy{}
+	}
+	return policies, nil
+}
+
+// SaveGlobal sets the global policy
+func (r *RetentionRepository) SaveGlobal(ctx context.Context, policy *model.RetentionPolicy) (*model.RetentionPolicy, error) {
+	query := `
+		INSERT INTO retention_policies (max_age_days, keep_latest, unread_max_age_days)
+		VALUES ($1, $2, $3)
+		ON CONFLICT ((feed_id IS NULL)) WHERE feed_id IS NULL
+		DO UPDATE SET max_age_days = $1, keep_latest = $2, unread_max_age_days = $3, updated_at = NOW()
+		RETURNING *`
+	var saved model.RetentionPolicy
+	err := r.db.GetContext(ctx, &saved, query, policy.MaxAgeDays, policy.KeepLatest, policy.UnreadMaxAgeDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save global retention policy: %w", err)
+	}
+	return &saved, nil
+}
+
+// SaveForFeed creates or replaces the policy of the feed
+func (r *RetentionRepository) SaveForFeed(ctx context.Context, feedID uuid.UUID, policy *model.RetentionPolicy) (*model.RetentionPolicy, error) {
+	query := `
+		INSERT INTO retention_policies (feed_id, max_age_days, keep_latest, unread_max_age_days)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (feed_id)
+		DO UPDATE SET max_age_days = $2, keep_latest = $3, unread_max_age_days = $4, updated_at = NOW()
+		RETURNING *`
+	var saved model.RetentionPolicy
+	err := r.db.GetContext(ctx, &saved, query, feedID, policy.MaxAgeDays, policy.KeepLatest, policy.UnreadMaxAgeDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save retention policy of feed %s: %w", feedID, err)
+	}
+	return &saved, nil
+}
+
+// DeleteForFeed deletes the policy of the feed. It returns false if the feed has no policy.
+func (r *RetentionRepository) DeleteForFeed(ctx context.Context, feedID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM retention_policies WHERE feed_id = $1", feedID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete retention policy of feed %s: %w", feedID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for retention policy deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// CountExpiredByFeed returns the number of articles the cleanup would delete per feed, most first
+func (r *RetentionRepository) CountExpiredByFeed(ctx context.Context) (model.RetentionFeedCounts, error) {
+	query := `
+		SELECT feed_id, COUNT(*) AS count
+		FROM articles
+		WHERE id IN (` + selectExpiredArticleIDs + `)
+		GROUP BY feed_id
+		ORDER BY count DESC`
+	var counts model.RetentionFeedCounts
+	err := r.db.SelectContext(ctx, &counts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count expired articles: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if counts == nil {
+		counts = model.RetentionFeedCounts{}
+	}
+	return counts, nil
+}
+
+// GetExpiredArticles returns up to limit of the articles the cleanup would delete, oldest first
+func (r *RetentionRepository) GetExpiredArticles(ctx context.Context, limit int) ([]*model.Article, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM articles
+		WHERE id IN (%s)
+		ORDER BY published_at, id
+		LIMIT $1`, selectArticleColumns(""), selectExpiredArticleIDs)
+	var articles []*model.Article
+	err := r.db.SelectContext(ctx, &articles, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired articles: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if articles == nil {
+		articles = []*model.Article{}
+	}
+	return articles, nil
+}
+
+// TrashExpiredArticles moves the articles expired by the retention policies to the trash and returns their
+// number per feed
+func (r *RetentionRepository) TrashExpiredArticles(ctx context.Context) (model.RetentionFeedCounts, error) {
+	query := `
+		WITH deleted AS (
+			UPDATE articles SET deleted_at = NOW()
+			WHERE id IN (` + selectExpiredArticleIDs + `)
+			RETURNING feed_id
+		)
+		SELECT feed_id, COUNT(*) AS count
+		FROM deleted
+		GROUP BY feed_id
+		ORDER BY count DESC`
+	var counts model.RetentionFeedCounts
+	err := r.db.SelectContext(ctx, &counts, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trash expired articles: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if counts == nil {
+		counts = model.RetentionFeedCounts{}
+	}
+	return counts, nil
+}
+
+func (r *RetentionRepository) CreateRun(ctx context.Context, run *model.RetentionRun) (*model.RetentionRun, error) {
+	query := `
+		INSERT INTO retention_runs (id, started_at, finished_at, deleted_count, feeds, error)
+		VALUES (:id, :started_at, :finished_at, :deleted_count, :feeds, :error)`
+	_, err := r.db.NamedExecContext(ctx, query, run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retention run: %w", err)
+	}
+	return run, nil
+}
+
+// GetRuns returns the latest runs, newest first
+func (r *RetentionRepository) GetRuns(ctx context.Context, limit int) ([]*model.RetentionRun, error) {
+	var runs []*model.RetentionRun
+	err := r.db.SelectContext(ctx, &runs, "SELECT * FROM retention_runs ORDER BY started_at DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention runs: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if runs == nil {
+		runs = []*model.RetentionRun{}
+	}
+	return runs, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type RuleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRuleRepository(db *sqlx.DB) *RuleRepository {
+	return &RuleRepository{db: db}
+}
+
+func (r *RuleRepository) GetAll(ctx context.Context) ([]*model.Rule, error) {
+	var rules []*model.Rule
+	err := r.db.SelectContext(ctx, &rules, "SELECT * FROM rules ORDER BY created_at, id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all rules: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if rules == nil {
+		rules = []*model.Rule{}
+	}
+	return rules, nil
+}
+
+// GetEnabledByFeedID returns the enabled rules of the feed and of all feeds in the order of their creation
+func (r *RuleRepository) GetEnabledByFeedID(ctx context.Context, feedID uuid.UUID) ([]*model.Rule, error) {
+	query := `
+		SELECT * FROM rules
+		WHERE enabled AND (feed_id IS NULL OR feed_id = $1)
+		ORDER BY created_at, id`
+	var rules []*model.Rule
+	err := r.db.SelectContext(ctx, &rules, query, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules of feed %s: %w", feedID, err)
+	}
+	return rules, nil
+}
+
+func (r *RuleRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Rule, error) {
+	var rule model.Rule
+	err := r.db.GetContext(ctx, &rule, "SELECT * FROM rules WHERE id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule by ID: %w", err)
+	}
+	return &rule, nil
+}
+
+func (r *RuleRepository) Create(ctx context.Context, rule *model.Rule) (*model.Rule, error) {
+	query := `
+		INSERT INTO rules (id, name, feed_id, conditions, actions, enabled, created_at, updated_at)
+		VALUES (:id, :name, :feed_id, :conditions, :actions, :enabled, :created_at, :updated_at)`
+	_, err := r.db.NamedExecContext(ctx, query, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return rule, nil
+}
+
+// Update replaces the definition of the rule, the match statistics are kept.
+// It returns false if the rule doesn't exist.
+func (r *RuleRepository) Update(ctx context.Context, rule *model.Rule) (bool, error) {
+	query := `
+		UPDATE rules
+		SET name = :name, feed_id = :feed_id, conditions = :conditions, actions = :actions, enabled = :enabled, updated_at = NOW()
+		WHERE id = :id`
+	result, err := r.db.NamedExecContext(ctx, query, rule)
+	if err != nil {
+		return false, fmt.Errorf("failed to update rule %s: %w", rule.ID, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for rule update: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete deletes the rule. It returns false if the rule doesn't exist.
+func (r *RuleRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rules WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete rule %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for rule deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// AddMatches adds the number of matched articles to the statistics of each rule
+func (r *RuleRepository) AddMatches(ctx context.Context, matches map[uuid.UUID]int) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(matches))
+	counts := make([]int64, 0, len(matches))
+	for id, count := range matches {
+		ids = append(ids, id)
+		counts = append(counts, int64(count))
+	}
+
+	query := `
+		UPDATE rules r
+		SET match_count = r.match_count + m.count, last_matched_at = NOW()
+		FROM unnest($1::uuid[], $2::int[]) AS m(id, count)
+		WHERE r.id = m.id`
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(counts))
+	if err != nil {
+		return fmt.Errorf("failed to add rule matches: %w", err)
+	}
+	return nil
+}
+
+// RuleRewrite changes the actions of a rule and may disable it
+type RuleRewrite func(rule *model.Rule)
+
+// rewriteTaggingRules applies the rewrite to the rules with a tag action of one of the tags and stores them in
+// the transaction
+func rewriteTaggingRules(ctx context.Context, tx *sqlx.Tx, tagIDs []uuid.UUID, rewrite RuleRewrite) error {
+	query := `
+		SELECT id, actions, enabled FROM rules
+		WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(actions) a WHERE a->>'tagId' = ANY($1::text[]))
+		FOR UPDATE`
+	var rules []*model.Rule
+	if err := tx.SelectContext(ctx, &rules, query, pq.Array(tagIDs)); err != nil {
+		return fmt.Errorf("failed to get rules tagging with tags %v: %w", tagIDs, err)
+	}
+
+	for _, rule := range rules {
+		rewrite(rule)
+		query := `
+			UPDATE rules
+			SET actions = $2, enabled = $3, updated_at = NOW()
+			WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, rule.ID, rule.Actions, rule.Enabled); err != nil {
+			return fmt.Errorf("failed to update actions of rule %s: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
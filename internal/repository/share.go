@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type ShareRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareRepository(db *sqlx.DB) *ShareRepository {
+	return &ShareRepository{db: db}
+}
+
+// selectShares selects the shares with the title of their article or collection.
+// The WHERE clause is inserted by the caller.
+const selectShares = `
+	SELECT s.*, COALESCE(a.title, c.name, '') AS title
+	FROM shares s
+	LEFT JOIN articles a ON a.id = s.article_id
+	LEFT JOIN collections c ON c.id = s.collection_id
+	%s`
+
+// activeShare matches the shares that have not expired
+const activeShare = "(s.expires_at IS NULL OR s.expires_at > NOW())"
+
+// GetActive returns the shares that have not expired, newest first
+func (r *ShareRepository) GetActive(ctx context.Context) ([]*model.Share, error) {
+	query := fmt.Sprintf(selectShares, "WHERE "+activeShare+" ORDER BY s.created_at DESC, s.id")
+	var shares []*model.Share
+	err := r.db.SelectContext(ctx, &shares, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active shares: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if shares == nil {
+		shares = []*model.Share{}
+	}
+	return shares, nil
+}
+
+func (r *ShareRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Share, error) {
+	query := fmt.Sprintf(selectShares, "WHERE s.id = $1")
+	var share model.Share
+	err := r.db.GetContext(ctx, &share, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share by ID: %w", err)
+	}
+	return &share, nil
+}
+
+// GetActiveByToken returns the share with the token if it has not expired
+func (r *ShareRepository) GetActiveByToken(ctx context.Context, token string) (*model.Share, error) {
+	query := fmt.Sprintf(selectShares, "WHERE s.token = $1 AND "+activeShare)
+	var share model.Share
+	err := r.db.GetContext(ctx, &share, query, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share by token: %w", err)
+	}
+	return &share, nil
+}
+
+func (r *ShareRepository) Create(ctx context.Context, share *model.Share) error {
+	query := `
+		INSERT INTO shares (id, token, article_id, collection_id, created_at, expires_at)
+		VALUES (:id, :token, :article_id, :collection_id, :created_at, :expires_at)`
+	_, err := r.db.NamedExecContext(ctx, query, share)
+	if err != nil {
+		return fmt.Errorf("failed to create share: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes the share, its token stops working. It returns false if the share doesn't exist.
+func (r *ShareRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM shares WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete share %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for share deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
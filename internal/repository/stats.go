@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type StatsRepository struct {
+	db *sqlx.DB
+}
+
+func NewStatsRepository(db *sqlx.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// statsMetricColumns maps the metrics of the time series to the article timestamp they are counted by
+var statsMetricColumns = []struct {
+	metric string
+	column string
+}{
+	{"published", "published_at"},
+	{"ingested", "ingested_at"},
+	{"read", "last_read_at"},
+	{"saved", "saved_at"},
+}
+
+// GetCounts counts the articles of each metric whose timestamp is in [from, to), grouped into buckets of the
+// interval starting in the time zone. Buckets without articles are left out. If feedID is set, only the
+// articles of the feed are counted. Trashed articles and the articles of trashed feeds are not counted.
+func (r *StatsRepository) GetCounts(ctx context.Context, interval model.StatsInterval, timeZone string, from, to time.Time, feedID *uuid.UUID) ([]*model.StatsMetricCount, error) {
+	feedCondition := ""
+	if feedID != nil {
+		feedCondition = "AND feed_id = $5"
+	}
+
+	// each metric is a range scan on the index of its column, or of the feed and its column
+	queries := make([]string, len(statsMetricColumns))
+	for i, m := range statsMetricColumns {
+		queries[i] = fmt.Sprintf(`
+		SELECT '%[1]s' AS metric, date_trunc($1, %[2]s AT TIME ZONE $2) AT TIME ZONE $2 AS bucket, COUNT(*) AS count
+		FROM articles
+		WHERE %[2]s >= $3 AND %[2]s < $4 AND %[3]s %[4]s
+		GROUP BY 2`, m.metric, m.column, notTrashed("articles"), feedCondition)
+	}
+	query := strings.Join(queries, "\n\t\tUNION ALL")
+
+	args := []any{string(interval), timeZone, from, to}
+	if feedID != nil {
+		args = append(args, *feedID)
+	}
+	var counts []*model.StatsMetricCount
+	err := r.db.SelectContext(ctx, &counts, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article statistics: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if counts == nil {
+		counts = []*model.StatsMetricCount{}
+	}
+	return counts, nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type StoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewStoryRepository(db *sqlx.DB) *StoryRepository {
+	return &StoryRepository{db: db}
+}
+
+// GetClusterCandidates returns up to limit fingerprinted articles published within the given range with one of
+// the probed SimHash band values, newest first. The article itself and other articles of its feed
+// are excluded.
+func (r *StoryRepository) GetClusterCandidates(ctx context.Context, article *model.Article, probes []int64, from, to time.Time, limit int) ([]*model.ClusterCandidate, error) {
+	query := fmt.Sprintf(`
+		SELECT id, feed_id, simhash, story_cluster_id, published_at
+		FROM articles
+		WHERE simhash_bands && $5::int[]
+		  AND published_at BETWEEN $1 AND $2
+		  AND %s
+		  AND simhash != 0
+		  AND id != $3
+		  AND ($4::uuid IS NULL OR feed_id IS NULL OR feed_id != $4)
+		ORDER BY published_at DESC
+		LIMIT $6`, notTrashed("articles"))
+	var candidates []*model.ClusterCandidate
+	err := r.db.SelectContext(ctx, &candidates, query, from, to, article.ID, article.FeedID, pq.Array(probes), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// CreateCluster creates a new cluster containing the given articles.
+// The earliest published article becomes the representative.
+func (r *StoryRepository) CreateCluster(ctx context.Context, articleIDs []uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	clusterID := uuid.New()
+	_, err = tx.ExecContext(ctx, "INSERT INTO story_clusters (id) VALUES ($1)", clusterID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create story cluster: %w", err)
+	}
+
+	query, args, err := sqlx.In("UPDATE articles SET story_cluster_id = ? WHERE id IN (?)", clusterID, articleIDs)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to prepare query for cluster members: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to add articles to story cluster: %w", err)
+	}
+
+	if err := updateRepresentative(ctx, tx, clusterID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit story cluster: %w", err)
+	}
+	return clusterID, nil
+}
+
+// AddToCluster adds the article to an existing cluster and updates its representative.
+func (r *StoryRepository) AddToCluster(ctx context.Context, clusterID, articleID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "UPDATE articles SET story_cluster_id = $1 WHERE id = $2", clusterID, articleID)
+	if err != nil {
+		return fmt.Errorf("failed to add article %s to story cluster %s: %w", articleID, clusterID, err)
+	}
+
+	if err := updateRepresentative(ctx, tx, clusterID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit story cluster: %w", err)
+	}
+	return nil
+}
+
+func updateRepresentative(ctx context.Context, tx *sqlx.Tx, clusterID uuid.UUID) error {
+	query := `
+		UPDATE story_clusters
+		SET updated_at = NOW(),
+		    representative_article_id = (
+		        SELECT id FROM articles
+		        WHERE story_cluster_id = $1
+		        ORDER BY deleted_at IS NOT NULL, published_at ASC, id ASC
+		        LIMIT 1)
+		WHERE id = $1`
+	_, err := tx.ExecContext(ctx, query, clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to update representative of story cluster %s: %w", clusterID, err)
+	}
+	return nil
+}
+
+// GetPaginated returns clusters with at least two articles, most recently updated first.
+func (r *StoryRepository) GetPaginated(ctx context.Context, offset, limit int) ([]*model.StoryCluster, error) {
+	query := fmt.Sprintf(`
+		SELECT c.id, c.representative_article_id, c.created_at, c.updated_at, COUNT(a.id) AS article_count
+		FROM story_clusters c
+		JOIN articles a ON a.story_cluster_id = c.id AND %s
+		GROUP BY c.id
+		HAVING COUNT(a.id) > 1
+		ORDER BY c.updated_at DESC, c.id DESC
+		OFFSET $1 LIMIT $2`, notTrashed("a"))
+	var clusters []*model.StoryCluster
+	err := r.db.SelectContext(ctx, &clusters, query, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story clusters: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if clusters == nil {
+		clusters = []*model.StoryCluster{}
+	}
+	return clusters, nil
+}
+
+// GetMembers returns the articles of the given clusters, earliest first.
+func (r *StoryRepository) GetMembers(ctx context.Context, clusterIDs []uuid.UUID) ([]*model.Article, error) {
+	if len(clusterIDs) == 0 {
+		return []*model.Article{}, nil
+	}
+
+	query, args, err := sqlx.In(fmt.Sprintf(
+		"SELECT %s FROM articles WHERE story_cluster_id IN (?) AND %s ORDER BY published_at ASC, id ASC",
+		selectArticleColumns(""), notTrashed("articles")), clusterIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare query for cluster IDs: %w", err)
+	}
+	query = r.db.Rebind(query) // rebind because of ? parameter in query
+
+	var articles []*model.Article
+	err = r.db.SelectContext(ctx, &articles, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story cluster members: %w", err)
+	}
+	return articles, nil
+}
+
+// DeleteEmptyClusters removes clusters without articles and reassigns representatives
+// of clusters whose representative was deleted or trashed.
+func (r *StoryRepository) DeleteEmptyClusters(ctx context.Context) error {
+	query := `
+		DELETE FROM story_clusters c
+		WHERE NOT EXISTS (SELECT 1 FROM articles a WHERE a.story_cluster_id = c.id)`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to delete empty story clusters: %w", err)
+	}
+
+	query = `
+		UPDATE story_clusters c
+		SET representative_article_id = (
+		    SELECT id FROM articles a
+		    WHERE a.story_cluster_id = c.id
+		    ORDER BY deleted_at IS NOT NULL, published_at ASC, id ASC
+		    LIMIT 1)
+		WHERE representative_article_id IS NULL
+		   OR EXISTS (SELECT 1 FROM articles r WHERE r.id = c.representative_article_id AND r.deleted_at IS NOT NULL)`
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to reassign story cluster representatives: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type TagRepository struct {
+	db *sqlx.DB
+}
+
+func NewTagRepository(db *sqlx.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// selectTagsWithCounts selects the tags together with their number of articles, articles in the trash or of
+// a trashed feed are not counted.
+// The WHERE clause is inserted by the caller.
+const selectTagsWithCounts = `
+	SELECT t.id, t.name, t.created_at, COUNT(a.article_id) AS article_count
+	FROM tags t
+	LEFT JOIN article_tags a ON a.tag_id = t.id
+		AND EXISTS (
+			SELECT 1 FROM articles d LEFT JOIN feeds df ON df.id = d.feed_id
+			WHERE d.id = a.article_id AND d.deleted_at IS NULL AND df.deleted_at IS NULL)
+	%s
+	GROUP BY t.id
+	ORDER BY LOWER(t.name)`
+
+func (r *TagRepository) GetAll(ctx context.Context) ([]*model.Tag, error) {
+	query := fmt.Sprintf(selectTagsWithCounts, "")
+	var tags []*model.Tag
+	err := r.db.SelectContext(ctx, &tags, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tags: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if tags == nil {
+		tags = []*model.Tag{}
+	}
+	return tags, nil
+}
+
+func (r *TagRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	query := fmt.Sprintf(selectTagsWithCounts, "WHERE t.id = $1")
+	var tag model.Tag
+	err := r.db.GetContext(ctx, &tag, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag by ID: %w", err)
+	}
+	return &tag, nil
+}
+
+// IsNameExists checks case-insensitively whether a tag other than excludeID has the name.
+func (r *TagRepository) IsNameExists(ctx context.Context, name string, excludeID *uuid.UUID) (bool, error) {
+	query := "SELECT COUNT(*) FROM tags WHERE LOWER(name) = LOWER($1) AND ($2::uuid IS NULL OR id != $2)"
+	var count int
+	err := r.db.GetContext(ctx, &count, query, name, excludeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if tag name exists: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *TagRepository) Create(ctx context.Context, tag *model.Tag) (*model.Tag, error) {
+	query := `
+		INSERT INTO tags (id, name, created_at)
+		VALUES (:id, :name, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, query, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	tag.ArticleCount = 0
+	return tag, nil
+}
+
+// Rename changes the name of the tag. It returns false if the tag doesn't exist.
+func (r *TagRepository) Rename(ctx context.Context, id uuid.UUID, name string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "UPDATE tags SET name = $2 WHERE id = $1", id, name)
+	if err != nil {
+		return false, fmt.Errorf("failed to rename tag %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for tag rename: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Delete removes the tag from all articles, applies the rewrite to the rules tagging with it and deletes it in
+// one transaction. It returns false if the tag doesn't exist.
+func (r *TagRepository) Delete(ctx context.Context, id uuid.UUID, rewrite RuleRewrite) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := rewriteTaggingRules(ctx, tx, []uuid.UUID{id}, rewrite); err != nil {
+		return false, err
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE id = $1", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete tag %s: %w", id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for tag deletion: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit tag deletion: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Merge moves the articles of the source tags to the target tag, applies the rewrite to the rules tagging with
+// the source tags and deletes the source tags in one transaction.
+func (r *TagRepository) Merge(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID, rewrite RuleRewrite) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO article_tags (article_id, tag_id, created_at)
+		SELECT article_id, $1, MIN(created_at)
+		FROM article_tags
+		WHERE tag_id = ANY($2)
+		GROUP BY article_id
+		ON CONFLICT (article_id, tag_id) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, query, targetID, pq.Array(sourceIDs)); err != nil {
+		return fmt.Errorf("failed to move articles to tag %s: %w", targetID, err)
+	}
+	if err := rewriteTaggingRules(ctx, tx, sourceIDs, rewrite); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ANY($1)", pq.Array(sourceIDs)); err != nil {
+		return fmt.Errorf("failed to delete merged tags: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tag merge: %w", err)
+	}
+	return nil
+}
+
+func (r *TagRepository) AddToArticle(ctx context.Context, articleID, tagID uuid.UUID) error {
+	query := `
+		INSERT INTO article_tags (article_id, tag_id)
+		VALUES ($1, $2)
+		ON CONFLICT (article_id, tag_id) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, articleID, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to add tag %s to article %s: %w", tagID, articleID, err)
+	}
+	return nil
+}
+
+func (r *TagRepository) RemoveFromArticle(ctx context.Context, articleID, tagID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM article_tags WHERE article_id = $1 AND tag_id = $2", articleID, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to remove tag %s from article %s: %w", tagID, articleID, err)
+	}
+	return nil
+}
+
+// GetByArticleIDs returns the tags of the given articles by article ID, sorted by name.
+func (r *TagRepository) GetByArticleIDs(ctx context.Context, articleIDs []uuid.UUID) (map[uuid.UUID][]*model.ArticleTag, error) {
+	tagsByArticle := make(map[uuid.UUID][]*model.ArticleTag)
+	if len(articleIDs) == 0 {
+		return tagsByArticle, nil
+	}
+
+	query := `
+		SELECT a.article_id, t.id, t.name
+		FROM article_tags a
+		JOIN tags t ON t.id = a.tag_id
+		WHERE a.article_id = ANY($1)
+		ORDER BY LOWER(t.name)`
+	var rows []struct {
+		ArticleID uuid.UUID `db:"article_id"`
+		model.ArticleTag
+	}
+	err := r.db.SelectContext(ctx, &rows, query, pq.Array(articleIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags of articles: %w", err)
+	}
+	for _, row := range rows {
+		tag := row.ArticleTag
+		tagsByArticle[row.ArticleID] = append(tagsByArticle[row.ArticleID], &tag)
+	}
+	return tagsByArticle, nil
+}
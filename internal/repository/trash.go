@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+type TrashRepository struct {
+	db *sqlx.DB
+}
+
+func NewTrashRepository(db *sqlx.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// GetFeeds returns the trashed feeds, most recently trashed first
+func (r *TrashRepository) GetFeeds(ctx context.Context) ([]*model.Feed, error) {
+	query := fmt.Sprintf(selectFeedsWithCounts, "WHERE f.deleted_at IS NOT NULL", "ORDER BY f.deleted_at DESC, f.id DESC")
+	var feeds []*model.Feed
+	err := r.db.SelectContext(ctx, &feeds, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed feeds: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if feeds == nil {
+		feeds = []*model.Feed{}
+	}
+	return feeds, nil
+}
+
+// GetArticles returns up to limit trashed articles, most recently trashed first, starting after the given
+// article if after is not nil
+func (r *TrashRepository) GetArticles(ctx context.Context, after *model.Article, limit int) ([]*model.Article, error) {
+	var where whereBuilder
+	where.add("deleted_at IS NOT NULL")
+	if after != nil {
+		where.add("(deleted_at, id) < (?, ?)", *after.DeletedAt, after.ID)
+	}
+	query := fmt.Sprintf("SELECT %s FROM articles %s ORDER BY deleted_at DESC, id DESC LIMIT %s",
+		selectArticleColumns(""), where.sql(), where.arg(limit))
+
+	var articles []*model.Article
+	err := r.db.SelectContext(ctx, &articles, query, where.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed articles: %w", err)
+	}
+	// Ensure empty slice, not nil, if no results
+	if articles == nil {
+		articles = []*model.Article{}
+	}
+	return articles, nil
+}
+
+// RestoreFeed takes the feed out of the trash. It returns false if the feed isn't trashed.
+func (r *TrashRepository) RestoreFeed(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.restore(ctx, "feeds", id)
+}
+
+// RestoreArticle takes the article out of the trash. It returns false if the article isn't trashed.
+func (r *TrashRepository) RestoreArticle(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.restore(ctx, "articles", id)
+}
+
+func (r *TrashRepository) restore(ctx context.Context, table string, id uuid.UUID) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL", table)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore %s %s: %w", table, id, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected for restore: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// Purge permanently deletes the feeds and articles trashed before the given time, together with the articles
+// of the purged feeds.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) (*model.TrashPurgeResult, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &model.TrashPurgeResult{}
+	articles, err := tx.ExecContext(ctx, `
+		DELETE FROM articles
+		WHERE deleted_at < $1 OR feed_id IN (SELECT id FROM feeds WHERE deleted_at < $1)`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trashed articles: %w", err)
+	}
+	feeds, err := tx.ExecContext(ctx, "DELETE FROM feeds WHERE deleted_at < $1", before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trashed feeds: %w", err)
+	}
+	articleCount, err := articles.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for article purge: %w", err)
+	}
+	feedCount, err := feeds.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected for feed purge: %w", err)
+	}
+	result.Articles, result.Feeds = int(articleCount), int(feedCount)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit trash purge: %w", err)
+	}
+	return result, nil
+}
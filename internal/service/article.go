@@ -4,28 +4,52 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lucasg04/fyrss-server/internal/model"
 	"github.com/lucasg04/fyrss-server/internal/repository"
 )
 
-var ErrDuplicateArticle = errors.New("duplicate article found")
+var (
+	ErrDuplicateArticle     = errors.New("duplicate article found")
+	ErrEmptySearchQuery     = errors.New("search query cannot be empty")
+	ErrInvalidArticleFilter = errors.New("invalid article filter")
+	ErrInvalidPagination    = errors.New("invalid pagination")
+	ErrInvalidBulkRequest   = errors.New("invalid bulk request")
+	ErrInvalidDwell         = errors.New("invalid dwell time")
+)
+
+const (
+	// defaultPageSize is the number of articles of a page if no limit is given
+	defaultPageSize = 20
+	// maxBulkIDs is the largest number of article IDs of a bulk request
+	maxBulkIDs = 1000
+)
 
 type ArticleService struct {
-	repo *repository.ArticleRepository
+	repo     *repository.ArticleRepository
+	tagRepo  *repository.TagRepository
+	feedRepo *repository.FeedRepository
 }
 
-func NewArticleService(repo *repository.ArticleRepository) *ArticleService {
-	return &ArticleService{repo: repo}
+func NewArticleService(repo *repository.ArticleRepository, tagRepo *repository.TagRepository, feedRepo *repository.FeedRepository) *ArticleService {
+	return &ArticleService{repo: repo, tagRepo: tagRepo, feedRepo: feedRepo}
 }
 
-func (s *ArticleService) GetAll(ctx context.Context) ([]*model.Article, error) {
-	articles, err := s.repo.GetAll(ctx)
+func (s *ArticleService) GetAll(ctx context.Context, filter model.ArticleFilter) ([]*model.Article, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+
+	articles, err := s.repo.GetAll(ctx, restrictToSortable(filter))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all articles: %w", err)
 	}
+	if err := s.attachTags(ctx, articles); err != nil {
+		return nil, err
+	}
 	return articles, nil
 }
 
@@ -34,135 +58,143 @@ func (s *ArticleService) GetByID(ctx context.Context, id uuid.UUID) (*model.Arti
 	if err != nil {
 		return nil, fmt.Errorf("failed to get article with ID %s: %w", id, err)
 	}
+	if err := s.attachTags(ctx, []*model.Article{article}); err != nil {
+		return nil, err
+	}
 	return article, nil
 }
 
-func (s *ArticleService) GetPaginatedByFeedID(ctx context.Context, feedID uuid.UUID, from, to int) ([]*model.Article, error) {
+func (s *ArticleService) GetPaginatedByFeedID(ctx context.Context, feedID uuid.UUID, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
 	if feedID == uuid.Nil {
 		return nil, fmt.Errorf("invalid feed ID: %s", feedID)
 	}
-
-	fullFeed, err := s.repo.GetAllOfFeedSortedByRecent(ctx, feedID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get feed: %w", err)
-	}
-	if len(fullFeed) == 0 {
-		return []*model.Article{}, nil // Return empty slice if no articles found
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
 	}
-	// adjust from & to params if articles length doesn't match
-	if from < 0 || to > len(fullFeed) || from >= to {
-		from = 0
-		to = len(fullFeed)
+	if len(filter.FeedIDs) > 0 {
+		return nil, fmt.Errorf("%w: feedId cannot be combined with the feed of the path", ErrInvalidArticleFilter)
 	}
 
-	sortedFeed := s.SortFeedArticles(ctx, fullFeed)
-
-	fullArticles, err := s.GetArticlesFromMinimal(ctx, sortedFeed[from:to])
-	if err != nil {
-		return nil, fmt.Errorf("failed to get full articles from minimal feed: %w", err)
-	}
-	return fullArticles, nil
+	filter.FeedIDs = []uuid.UUID{feedID}
+	return s.getPage(ctx, filter, model.ArticleSortNewest, page)
 }
 
-func (s *ArticleService) SortFeedArticles(ctx context.Context, articles []*model.MinimalFeedArticle) []*model.MinimalFeedArticle {
-	if len(articles) == 0 {
-		return []*model.MinimalFeedArticle{}
+// GetPaginatedByFeedIDs returns a page of the articles of any of the feeds, the page is empty if there are no feeds
+func (s *ArticleService) GetPaginatedByFeedIDs(ctx context.Context, feedIDs []uuid.UUID, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+	if len(filter.FeedIDs) > 0 {
+		return nil, fmt.Errorf("%w: feedId cannot be combined with the feeds of the path", ErrInvalidArticleFilter)
 	}
 
-	// Simple sort by published date (newest first)
-	sort.SliceStable(articles, func(i, j int) bool {
-		if !articles[i].PublishedAt.Equal(articles[j].PublishedAt) {
-			return articles[i].PublishedAt.After(articles[j].PublishedAt)
+	if len(feedIDs) == 0 {
+		// an empty feed filter would match all articles
+		result := &model.ArticlePage{Articles: []*model.Article{}}
+		if page.IncludeTotal {
+			total := 0
+			result.TotalCount = &total
 		}
-		// Deterministic fallback by ID
-		return articles[i].ID.String() > articles[j].ID.String()
-	})
-
-	return articles
-}
-
-func (s *ArticleService) DeleteOneWeekOldArticles(ctx context.Context) error {
-	err := s.repo.DeleteOneWeekOldArticles(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to delete articles older than one week: %w", err)
+		return result, nil
 	}
-	return nil
+	filter.FeedIDs = feedIDs
+	return s.getPage(ctx, filter, model.ArticleSortNewest, page)
 }
 
-func (s *ArticleService) GetHistoryPaginated(ctx context.Context, from, to int) ([]*model.Article, error) {
-	articles, err := s.repo.GetFullHistorySorted(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get article history: %w", err)
-	}
-
-	if len(articles) == 0 {
-		return []*model.Article{}, nil // Return empty slice if no articles found
+// GetTimeline returns a page of the articles of all feeds ordered by their publish date,
+// each with the name and icon of its feed
+func (s *ArticleService) GetTimeline(ctx context.Context, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
 	}
-	// adjust from & to params if articles length doesnt match
-	if from < 0 || to > len(articles) || from >= to {
-		from = 0
-		to = len(articles)
+	if filter.Sort != "" && filter.Sort != model.ArticleSortNewest && filter.Sort != model.ArticleSortOldest {
+		return nil, fmt.Errorf("%w: the timeline can only be sorted by newest or oldest", ErrInvalidArticleFilter)
 	}
 
-	fullArticles, err := s.GetArticlesFromMinimal(ctx, articles[from:to])
+	result, err := s.getPage(ctx, filter, model.ArticleSortNewest, page)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get full articles from minimal history: %w", err)
+		return nil, err
+	}
+	if err := s.attachFeeds(ctx, result.Articles); err != nil {
+		return nil, err
 	}
-	return fullArticles, nil
+	return result, nil
 }
 
-func (s *ArticleService) GetSavedPaginated(ctx context.Context, from, to int) ([]*model.Article, error) {
-	articles, err := s.repo.GetAllSavedSorted(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get saved articles: %w", err)
+func (s *ArticleService) GetHistoryPaginated(ctx context.Context, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Read != nil && !*filter.Read {
+		return nil, fmt.Errorf("%w: the history only contains read articles", ErrInvalidArticleFilter)
 	}
 
-	if len(articles) == 0 {
-		return []*model.Article{}, nil // Return empty slice if no articles found
+	read := true
+	filter.Read = &read
+	return s.getPage(ctx, filter, model.ArticleSortRecentlyRead, page)
+}
+
+func (s *ArticleService) GetSavedPaginated(ctx context.Context, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
 	}
-	// adjust from & to params if articles length doesnt match
-	if from < 0 || to > len(articles) || from >= to {
-		from = 0
-		to = len(articles)
+	if filter.Saved != nil && !*filter.Saved {
+		return nil, fmt.Errorf("%w: saved articles cannot be filtered by saved=false", ErrInvalidArticleFilter)
 	}
 
-	fullArticles, err := s.GetArticlesFromMinimal(ctx, articles[from:to])
-	if err != nil {
-		return nil, fmt.Errorf("failed to get full articles from minimal saved: %w", err)
-	}
-	return fullArticles, nil
+	saved := true
+	filter.Saved = &saved
+	return s.getPage(ctx, filter, model.ArticleSortNewest, page)
 }
 
-func (s *ArticleService) GetArticlesFromMinimal(ctx context.Context, articles []*model.MinimalFeedArticle) ([]*model.Article, error) {
-	if len(articles) == 0 {
-		return []*model.Article{}, nil // Return empty slice if no articles provided
+// getPage returns a page of the articles matching the filter, ordered by the sort of the filter or by defaultSort.
+func (s *ArticleService) getPage(ctx context.Context, filter model.ArticleFilter, defaultSort model.ArticleSort, page model.PageRequest) (*model.ArticlePage, error) {
+	if filter.Sort == "" {
+		filter.Sort = defaultSort
+	}
+	filter = restrictToSortable(filter)
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	if page.Limit < 0 || page.Offset < 0 {
+		return nil, fmt.Errorf("%w: limit and offset must not be negative", ErrInvalidPagination)
+	}
+	if page.Cursor != "" && page.Offset > 0 {
+		return nil, fmt.Errorf("%w: a cursor cannot be combined with an offset", ErrInvalidPagination)
 	}
 
-	// Extract IDs from minimal articles
-	ids := make([]uuid.UUID, len(articles))
-	for i, article := range articles {
-		ids[i] = article.ID
+	var cursor *model.ArticleCursor
+	if page.Cursor != "" {
+		var err error
+		cursor, err = decodeArticleCursor(page.Cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Fetch full articles by IDs
-	fullUnsortedArticles, err := s.repo.GetByIDs(ctx, ids)
+	// one more article than requested tells whether there is a next page
+	articles, err := s.repo.GetPage(ctx, filter, cursor, page.Offset, page.Limit+1)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get full articles by minimal articles: %w", err)
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	result := &model.ArticlePage{Articles: articles}
+	if len(articles) > page.Limit {
+		result.Articles = articles[:page.Limit]
+		result.NextCursor = encodeArticleCursor(articleCursorAfter(result.Articles[page.Limit-1], filter.Sort))
 	}
-	fullArticlesMap := make(map[uuid.UUID]*model.Article, len(articles))
-	for _, article := range fullUnsortedArticles {
-		fullArticlesMap[article.ID] = article
+	if err := s.attachTags(ctx, result.Articles); err != nil {
+		return nil, err
 	}
 
-	// Restore original order
-	fullArticles := make([]*model.Article, len(articles))
-	for i, article := range articles {
-		if fullArticle, exists := fullArticlesMap[article.ID]; exists {
-			fullArticles[i] = fullArticle
+	if page.IncludeTotal {
+		total, err := s.repo.Count(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count articles: %w", err)
 		}
+		result.TotalCount = &total
 	}
-
-	return fullArticles, nil
+	return result, nil
 }
 
 func (s *ArticleService) UpdateSavedByID(ctx context.Context, id uuid.UUID, saved bool) error {
@@ -170,27 +202,41 @@ func (s *ArticleService) UpdateSavedByID(ctx context.Context, id uuid.UUID, save
 		return fmt.Errorf("invalid article ID: %s", id)
 	}
 
-	err := s.repo.UpdateSavedByID(ctx, id, saved)
+	found, err := s.repo.UpdateSavedByID(ctx, id, saved)
 	if err != nil {
 		return fmt.Errorf("failed to update saved status for article ID %s: %w", id, err)
 	}
+	if !found {
+		return ErrArticleNotFound
+	}
 	return nil
 }
 
+// IsDuplicate reports whether an article with the same content hash is already saved or was skipped by a rule
+func (s *ArticleService) IsDuplicate(ctx context.Context, article *model.Article) (bool, error) {
+	isDuplicate, err := s.repo.IsDuplicate(ctx, article.ContentHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for duplicate article: %w", err)
+	}
+	return isDuplicate, nil
+}
+
 func (s *ArticleService) Save(ctx context.Context, article *model.Article) error {
 	if article == nil {
 		return fmt.Errorf("article cannot be nil")
 	}
 
-	// Check if the article is a duplicate
-	isDuplicate, err := s.repo.IsDuplicate(ctx, article.ContentHash)
+	isDuplicate, err := s.IsDuplicate(ctx, article)
 	if err != nil {
-		return fmt.Errorf("failed to check for duplicate article: %w", err)
+		return err
 	}
 	if isDuplicate {
 		return ErrDuplicateArticle
 	}
 
+	applyReadingStats(article)
+	article.SimHash = articleFingerprint(article)
+
 	_, err = s.repo.Save(ctx, article)
 	if err != nil {
 		return fmt.Errorf("failed to save article: %w", err)
@@ -199,14 +245,277 @@ func (s *ArticleService) Save(ctx context.Context, article *model.Article) error
 	return nil
 }
 
-func (s *ArticleService) UpdateReadByID(ctx context.Context, id uuid.UUID) error {
+// SaveSkipped remembers the content hash of an article skipped by a rule, so IsDuplicate recognizes it
+func (s *ArticleService) SaveSkipped(ctx context.Context, article *model.Article) error {
+	err := s.repo.SaveSkipped(ctx, article)
+	if err != nil {
+		return fmt.Errorf("failed to save skipped article: %w", err)
+	}
+	return nil
+}
+
+// UpdateReadByID marks the article as read or unread
+func (s *ArticleService) UpdateReadByID(ctx context.Context, id uuid.UUID, read bool) error {
 	if id == uuid.Nil {
 		return fmt.Errorf("invalid article ID: %s", id)
 	}
 
-	err := s.repo.UpdateReadByID(ctx, id)
+	found, err := s.repo.UpdateReadByID(ctx, id, read)
 	if err != nil {
 		return fmt.Errorf("failed to update read status for article ID %s: %w", id, err)
 	}
+	if !found {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+// UpdateOpenedByID records that the article was opened, it stays unread until it is marked as read
+func (s *ArticleService) UpdateOpenedByID(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("invalid article ID: %s", id)
+	}
+
+	found, err := s.repo.UpdateOpenedByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to update opened time for article ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+// maxDwellReport is the longest dwell time accepted in one report, longer reports are most likely a forgotten tab
+const maxDwellReport = time.Hour
+
+// AddDwellByID adds the time the article was open in the client to its dwell time
+func (s *ArticleService) AddDwellByID(ctx context.Context, id uuid.UUID, dwell time.Duration) error {
+	if id == uuid.Nil {
+		return fmt.Errorf("invalid article ID: %s", id)
+	}
+	if dwell < time.Second || dwell > maxDwellReport {
+		return fmt.Errorf("%w: dwell time must be between 1 and %d seconds", ErrInvalidDwell, int(maxDwellReport.Seconds()))
+	}
+
+	found, err := s.repo.AddDwellByID(ctx, id, int(dwell.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to add dwell time for article ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrArticleNotFound
+	}
+	return nil
+}
+
+// GetFetchedBefore returns the article of the feed with the same title, link and publish date as the fetched
+// article, nil if it was not fetched before. The link alone is not enough, some feeds link every item to their
+// site, and recurring items like a daily briefing even repeat the title.
+func (s *ArticleService) GetFetchedBefore(ctx context.Context, feedID uuid.UUID, fetched *model.Article) (*model.Article, error) {
+	if fetched.SourceUrl == "" {
+		return nil, nil
+	}
+	articles, err := s.repo.GetByTitleAndSourceURL(ctx, feedID, fetched.Title, fetched.SourceUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article fetched before: %w", err)
+	}
+	return samePublishedAt(articles, fetched), nil
+}
+
+// samePublishedAt returns the article published at the same time as the fetched one, nil if there is none.
+// Items without a publish date are dated to their fetch and never match.
+func samePublishedAt(articles []*model.Article, fetched *model.Article) *model.Article {
+	for _, article := range articles {
+		if article.PublishedAt.Equal(fetched.PublishedAt) {
+			return article
+		}
+	}
+	return nil
+}
+
+// UpdateContent replaces description and content of the article with the ones fetched again from its feed and
+// recomputes its reading statistics. It reports whether the content changed.
+func (s *ArticleService) UpdateContent(ctx context.Context, article, fetched *model.Article) (bool, error) {
+	if !applyFetchedContent(article, fetched) {
+		return false, nil
+	}
+
+	err := s.repo.UpdateContent(ctx, article)
+	if err != nil {
+		return false, fmt.Errorf("failed to update content for article ID %s: %w", article.ID, err)
+	}
+	return true, nil
+}
+
+// applyFetchedContent copies description and content of the fetched article to the article if they changed
+// and recomputes the reading statistics and the fingerprint derived from them
+func applyFetchedContent(article, fetched *model.Article) bool {
+	if article.Description == fetched.Description && article.Content == fetched.Content {
+		return false
+	}
+	article.Description = fetched.Description
+	article.Content = fetched.Content
+	applyReadingStats(article)
+	article.SimHash = articleFingerprint(article)
+	return true
+}
+
+// Search returns the articles between from and to matching the full-text search query, best matches first
+func (s *ArticleService) Search(ctx context.Context, query string, from, to int, filter model.ArticleFilter) ([]*model.ArticleSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if filter.Sort != "" {
+		return nil, fmt.Errorf("%w: search results are sorted by relevance", ErrInvalidArticleFilter)
+	}
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.Search(ctx, query, filter, from, to-from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %w", err)
+	}
+	articles := make([]*model.Article, len(results))
+	for i, result := range results {
+		articles[i] = &result.Article
+	}
+	if err := s.attachTags(ctx, articles); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Bulk applies the action of the request to the selected articles in one transaction
+func (s *ArticleService) Bulk(ctx context.Context, req *model.BulkArticleRequest) (*model.BulkArticleResult, error) {
+	switch req.Action {
+	case model.BulkActionRead, model.BulkActionUnread, model.BulkActionSave, model.BulkActionUnsave, model.BulkActionDelete:
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidBulkRequest, req.Action)
+	}
+	if (len(req.IDs) > 0) == (req.Filter != nil) {
+		return nil, fmt.Errorf("%w: either ids or filter is required", ErrInvalidBulkRequest)
+	}
+	if len(req.IDs) > maxBulkIDs {
+		return nil, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBulkRequest, maxBulkIDs)
+	}
+	for _, id := range req.IDs {
+		if id == uuid.Nil {
+			return nil, fmt.Errorf("%w: invalid article ID %s", ErrInvalidBulkRequest, id)
+		}
+	}
+
+	var filter model.BulkArticleFilter
+	if req.Filter != nil {
+		filter = *req.Filter
+		filter.Query = strings.TrimSpace(filter.Query)
+		// an empty filter would select every article
+		if filter.FeedID == nil && filter.OlderThan == nil && filter.Query == "" {
+			return nil, fmt.Errorf("%w: the filter needs feedId, olderThan or query", ErrInvalidBulkRequest)
+		}
+		if filter.FeedID != nil && *filter.FeedID == uuid.Nil {
+			return nil, fmt.Errorf("%w: invalid feedId %s", ErrInvalidBulkRequest, *filter.FeedID)
+		}
+	}
+
+	result, err := s.repo.Bulk(ctx, req.IDs, filter, req.Action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply bulk %s: %w", req.Action, err)
+	}
+	return result, nil
+}
+
+// MarkRead marks all articles published before the given time as read, only those of the feed if feedID is set
+func (s *ArticleService) MarkRead(ctx context.Context, feedID *uuid.UUID, before time.Time) (*model.BulkArticleResult, error) {
+	if feedID != nil && *feedID == uuid.Nil {
+		return nil, fmt.Errorf("invalid feed ID: %s", *feedID)
+	}
+
+	filter := model.BulkArticleFilter{FeedID: feedID, OlderThan: &before}
+	result, err := s.repo.Bulk(ctx, nil, filter, model.BulkActionRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark articles as read: %w", err)
+	}
+	return result, nil
+}
+
+// attachTags loads the tags of the articles
+func (s *ArticleService) attachTags(ctx context.Context, articles []*model.Article) error {
+	ids := make([]uuid.UUID, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	tags, err := s.tagRepo.GetByArticleIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get tags of articles: %w", err)
+	}
+	for _, article := range articles {
+		article.Tags = tags[article.ID]
+	}
+	return nil
+}
+
+// attachFeeds sets the name and icon of the feed of each article
+func (s *ArticleService) attachFeeds(ctx context.Context, articles []*model.Article) error {
+	ids := make([]uuid.UUID, 0, len(articles))
+	seen := make(map[uuid.UUID]bool)
+	for _, article := range articles {
+		if article.FeedID != nil && !seen[*article.FeedID] {
+			seen[*article.FeedID] = true
+			ids = append(ids, *article.FeedID)
+		}
+	}
+	feeds, err := s.feedRepo.GetArticleFeedsByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds of articles: %w", err)
+	}
+	for _, article := range articles {
+		if article.FeedID != nil {
+			article.Feed = feeds[*article.FeedID]
+		}
+	}
+	return nil
+}
+
+// validateArticleFilter rejects contradicting or out of range filter combinations
+func validateArticleFilter(filter model.ArticleFilter) error {
+	if filter.SourceType != "" && filter.SourceType != model.SourceTypeRSS && filter.SourceType != model.SourceTypeScraped {
+		return fmt.Errorf("%w: unknown sourceType %q", ErrInvalidArticleFilter, filter.SourceType)
+	}
+	if filter.MinReadingTime != nil && filter.MaxReadingTime != nil && *filter.MinReadingTime > *filter.MaxReadingTime {
+		return fmt.Errorf("%w: minReadingTime must not be greater than maxReadingTime", ErrInvalidArticleFilter)
+	}
+	if filter.PublishedFrom != nil && filter.PublishedTo != nil && filter.PublishedFrom.After(*filter.PublishedTo) {
+		return fmt.Errorf("%w: publishedFrom must not be after publishedTo", ErrInvalidArticleFilter)
+	}
+	if filter.ReadFrom != nil && filter.ReadTo != nil && filter.ReadFrom.After(*filter.ReadTo) {
+		return fmt.Errorf("%w: readFrom must not be after readTo", ErrInvalidArticleFilter)
+	}
+	if filter.Read != nil && !*filter.Read && (filter.ReadFrom != nil || filter.ReadTo != nil) {
+		return fmt.Errorf("%w: read=false cannot be combined with readFrom or readTo", ErrInvalidArticleFilter)
+	}
+	if filter.Read != nil && !*filter.Read && filter.Sort == model.ArticleSortRecentlyRead {
+		return fmt.Errorf("%w: read=false cannot be sorted by read date", ErrInvalidArticleFilter)
+	}
+	for _, feedID := range filter.FeedIDs {
+		if feedID == uuid.Nil {
+			return fmt.Errorf("%w: invalid feedId %s", ErrInvalidArticleFilter, feedID)
+		}
+	}
+	for _, tagID := range filter.TagIDs {
+		if tagID == uuid.Nil {
+			return fmt.Errorf("%w: invalid tagId %s", ErrInvalidArticleFilter, tagID)
+		}
+	}
 	return nil
 }
+
+// restrictToSortable limits the filter to articles having the sort key. Unread articles have no read date.
+func restrictToSortable(filter model.ArticleFilter) model.ArticleFilter {
+	if filter.Sort == model.ArticleSortRecentlyRead {
+		read := true
+		filter.Read = &read
+	}
+	return filter
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -8,66 +10,88 @@ import (
 	"github.com/lucasg04/fyrss-server/internal/model"
 )
 
-// func TestSortFeedArticles(t *testing.T) {
-// 	t.Run("no change with articles sorted by publishedAt with large time diffs", func(t *testing.T) {
-// 		articles := []*model.MinimalFeedArticle{
-// 			mockArticle(1, time.Now().Add(-2*time.Hour)),
-// 			mockArticle(1, time.Now().Add(-8*time.Hour)),
-// 			mockArticle(1, time.Now().Add(-15*time.Hour)),
-// 			mockArticle(1, time.Now().Add(-17*time.Hour)),
-// 			mockArticle(1, time.Now().Add(-27*time.Hour)),
-// 			mockArticle(1, time.Now().Add(-30*time.Hour)),
-// 		}
-// 		sortedArticles := SortFeedArticles(articles)
-// 		assertDateEquals(t, articles[0].PublishedAt, sortedArticles[0].PublishedAt)
-// 		assertDateEquals(t, articles[1].PublishedAt, sortedArticles[1].PublishedAt)
-// 		assertDateEquals(t, articles[2].PublishedAt, sortedArticles[2].PublishedAt)
-// 		assertDateEquals(t, articles[3].PublishedAt, sortedArticles[3].PublishedAt)
-// 		assertDateEquals(t, articles[4].PublishedAt, sortedArticles[4].PublishedAt)
-// 		assertDateEquals(t, articles[5].PublishedAt, sortedArticles[5].PublishedAt)
-// 	})
+func TestValidateArticleFilter(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	unread := false
+	two, five := 2, 5
 
-// 	t.Run("articles by priority and publishedAt", func(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  model.ArticleFilter
+		wantErr bool
+	}{
+		{"empty filter", model.ArticleFilter{}, false},
+		{"valid ranges", model.ArticleFilter{PublishedFrom: &earlier, PublishedTo: &now, MinReadingTime: &two, MaxReadingTime: &five}, false},
+		{"known source type", model.ArticleFilter{SourceType: model.SourceTypeScraped}, false},
+		{"unknown source type", model.ArticleFilter{SourceType: "podcast"}, true},
+		{"reversed publish range", model.ArticleFilter{PublishedFrom: &now, PublishedTo: &earlier}, true},
+		{"reversed read range", model.ArticleFilter{ReadFrom: &now, ReadTo: &earlier}, true},
+		{"reversed reading time", model.ArticleFilter{MinReadingTime: &five, MaxReadingTime: &two}, true},
+		{"unread with read range", model.ArticleFilter{Read: &unread, ReadFrom: &earlier}, true},
+		{"unread sorted by read date", model.ArticleFilter{Read: &unread, Sort: model.ArticleSortRecentlyRead}, true},
+		{"nil feed ID", model.ArticleFilter{FeedIDs: []uuid.UUID{uuid.Nil}}, true},
+		{"nil tag ID", model.ArticleFilter{TagIDs: []uuid.UUID{uuid.Nil}}, true},
+	}
 
-// 		articles := []*model.MinimalFeedArticle{
-// 			mockArticle("some description", time.Now()),
-// 			mockArticle("unknown", time.Now()),
-// 			mockArticle("5", time.Now()),
-// 			mockArticle("unknown", time.Now().Add(-2*time.Hour)),
-// 			mockArticle("1", time.Now()),
-// 			mockArticle("2", time.Now().Add(-2*time.Hour)),
-// 		}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArticleFilter(tt.filter)
+			if tt.wantErr && !errors.Is(err, ErrInvalidArticleFilter) {
+				t.Errorf("Expected ErrInvalidArticleFilter, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
 
-// 		sortedArticles := SortFeedArticles(articles)
+func TestBulkRequestValidation(t *testing.T) {
+	s := &ArticleService{}
+	feedID := uuid.New()
 
-// 		if len(sortedArticles) != 6 {
-// 			t.Fatalf("Expected 6 articles, got %d", len(sortedArticles))
-// 		}
-// 		assertSameID(t, articles[0], sortedArticles[1])
-// 		assertSameID(t, articles[1], sortedArticles[4])
-// 		assertSameID(t, articles[2], sortedArticles[3])
-// 		assertSameID(t, articles[3], sortedArticles[5])
-// 		assertSameID(t, articles[4], sortedArticles[0])
-// 		assertSameID(t, articles[5], sortedArticles[2])
-// 	})
-// }
+	tests := map[string]model.BulkArticleRequest{
+		"unknown action": {IDs: []uuid.UUID{uuid.New()}, Action: "archive"},
+		"no selection":   {Action: model.BulkActionRead},
+		"ids and filter": {IDs: []uuid.UUID{uuid.New()}, Filter: &model.BulkArticleFilter{FeedID: &feedID}, Action: model.BulkActionRead},
+		"empty filter":   {Filter: &model.BulkArticleFilter{Query: "  "}, Action: model.BulkActionDelete},
+		"nil id":         {IDs: []uuid.UUID{uuid.Nil}, Action: model.BulkActionSave},
+		"nil feed id":    {Filter: &model.BulkArticleFilter{FeedID: &uuid.Nil}, Action: model.BulkActionRead},
+		"too many ids":   {IDs: make([]uuid.UUID, maxBulkIDs+1), Action: model.BulkActionUnsave},
+	}
 
-func mockArticle(description string, publishedAt time.Time) *model.MinimalFeedArticle {
-	return &model.MinimalFeedArticle{
-		ID:          uuid.New(),
-		Description: description,
-		PublishedAt: publishedAt,
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Bulk(context.Background(), &req); !errors.Is(err, ErrInvalidBulkRequest) {
+				t.Errorf("Expected ErrInvalidBulkRequest, got %v", err)
+			}
+		})
 	}
 }
 
-func assertSameID(t *testing.T, a, b *model.MinimalFeedArticle) {
-	if a.ID != b.ID {
-		t.Errorf("Expected article ID %v, got %v", a.ID, b.ID)
+func TestSearch_RejectsSort(t *testing.T) {
+	s := &ArticleService{}
+	_, err := s.Search(context.Background(), "budget", 0, 10, model.ArticleFilter{Sort: model.ArticleSortOldest})
+	if !errors.Is(err, ErrInvalidArticleFilter) {
+		t.Errorf("Expected ErrInvalidArticleFilter, got %v", err)
 	}
 }
 
-func assertDateEquals(t *testing.T, a, b time.Time) {
-	if !a.Equal(b) {
-		t.Errorf("Expected date %v, got %v", a, b)
+func TestSamePublishedAt(t *testing.T) {
+	monday := time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)
+	tuesday := monday.Add(24 * time.Hour)
+	briefing := &model.Article{ID: uuid.New(), Title: "Morning briefing", SourceUrl: "https://example.com", PublishedAt: monday}
+
+	// the same item in another time zone is fetched before
+	fetched := &model.Article{Title: briefing.Title, SourceUrl: briefing.SourceUrl, PublishedAt: monday.In(time.FixedZone("CEST", 2*60*60))}
+	if got := samePublishedAt([]*model.Article{briefing}, fetched); got != briefing {
+		t.Errorf("Expected the article with the same publish date, got %v", got)
+	}
+
+	// a recurring item with the same title and link is new
+	fetched.PublishedAt = tuesday
+	if got := samePublishedAt([]*model.Article{briefing}, fetched); got != nil {
+		t.Errorf("Expected no article for a different publish date, got %v", got.ID)
 	}
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrInvalidCategoryName   = errors.New("invalid category name")
+	ErrDuplicateCategoryName = errors.New("category name already exists")
+	ErrInvalidCategoryOrder  = errors.New("invalid category order")
+)
+
+// maxCategoryNameLength is the longest category name in characters
+const maxCategoryNameLength = 50
+
+type CategoryService struct {
+	repo           *repository.CategoryRepository
+	feedRepo       *repository.FeedRepository
+	articleService *ArticleService
+}
+
+func NewCategoryService(repo *repository.CategoryRepository, feedRepo *repository.FeedRepository, articleService *ArticleService) *CategoryService {
+	return &CategoryService{repo: repo, feedRepo: feedRepo, articleService: articleService}
+}
+
+// GetAll returns all categories in their order, each with its ordered feeds and unread counts
+func (s *CategoryService) GetAll(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all categories: %w", err)
+	}
+	if err := s.attachFeeds(ctx, categories...); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (s *CategoryService) GetByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category with ID %s: %w", id, err)
+	}
+	if err := s.attachFeeds(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Create adds a category after all existing categories
+func (s *CategoryService) Create(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+	name, err := s.validateCategoryName(ctx, req.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	category := &model.Category{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Feeds:     []*model.Feed{},
+	}
+	createdCategory, err := s.repo.Create(ctx, category)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateCategoryName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return createdCategory, nil
+}
+
+func (s *CategoryService) Rename(ctx context.Context, id uuid.UUID, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	name, err := s.validateCategoryName(ctx, req.Name, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Rename(ctx, id, name)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateCategoryName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename category with ID %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrCategoryNotFound
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Delete deletes the category, its feeds become uncategorized
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) error {
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete category with ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// Reorder sets the order of the categories. The request must list every category exactly once.
+func (s *CategoryService) Reorder(ctx context.Context, req *model.ReorderCategoriesRequest) ([]*model.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all categories: %w", err)
+	}
+	if err := validateCategoryOrder(categories, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Reorder(ctx, req.CategoryIDs); err != nil {
+		return nil, fmt.Errorf("failed to reorder categories: %w", err)
+	}
+	return s.GetAll(ctx)
+}
+
+// SetFeeds makes the listed feeds the feeds of the category in the given order
+func (s *CategoryService) SetFeeds(ctx context.Context, id uuid.UUID, req *model.SetCategoryFeedsRequest) (*model.Category, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	seen := make(map[uuid.UUID]bool, len(req.FeedIDs))
+	for _, feedID := range req.FeedIDs {
+		if seen[feedID] {
+			return nil, fmt.Errorf("%w: feed %s is listed twice", ErrInvalidCategoryOrder, feedID)
+		}
+		seen[feedID] = true
+	}
+
+	found, err := s.repo.SetFeeds(ctx, id, req.FeedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set feeds of category %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrFeedNotFound
+	}
+	return s.GetByID(ctx, id)
+}
+
+// GetArticles returns a page of the articles of all feeds of the category
+func (s *CategoryService) GetArticles(ctx context.Context, id uuid.UUID, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	category, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	feedIDs := make([]uuid.UUID, len(category.Feeds))
+	for i, feed := range category.Feeds {
+		feedIDs[i] = feed.ID
+	}
+	return s.articleService.GetPaginatedByFeedIDs(ctx, feedIDs, page, filter)
+}
+
+// attachFeeds sets the feeds and the aggregated counts of the categories
+func (s *CategoryService) attachFeeds(ctx context.Context, categories ...*model.Category) error {
+	feeds, err := s.feedRepo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds of categories: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*model.Category, len(categories))
+	for _, category := range categories {
+		category.Feeds = []*model.Feed{}
+		category.UnreadCount = 0
+		category.NewSinceLastVisit = 0
+		byID[category.ID] = category
+	}
+	// the feeds are already ordered by their position
+	for _, feed := range feeds {
+		if feed.CategoryID == nil {
+			continue
+		}
+		category, ok := byID[*feed.CategoryID]
+		if !ok {
+			continue
+		}
+		category.Feeds = append(category.Feeds, feed)
+		category.UnreadCount += feed.UnreadCount
+		category.NewSinceLastVisit += feed.NewSinceLastVisit
+	}
+	return nil
+}
+
+// validateCategoryName returns the trimmed name if it is valid and not used by a category other than excludeID
+func (s *CategoryService) validateCategoryName(ctx context.Context, name string, excludeID *uuid.UUID) (string, error) {
+	name = normalizeName(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name cannot be empty", ErrInvalidCategoryName)
+	}
+	if utf8.RuneCountInString(name) > maxCategoryNameLength {
+		return "", fmt.Errorf("%w: name cannot be longer than %d characters", ErrInvalidCategoryName, maxCategoryNameLength)
+	}
+
+	exists, err := s.repo.IsNameExists(ctx, name, excludeID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for duplicate category name: %w", err)
+	}
+	if exists {
+		return "", ErrDuplicateCategoryName
+	}
+	return name, nil
+}
+
+// validateCategoryOrder checks that ids lists every category exactly once
+func validateCategoryOrder(categories []*model.Category, ids []uuid.UUID) error {
+	if len(ids) != len(categories) {
+		return fmt.Errorf("%w: expected %d categories, got %d", ErrInvalidCategoryOrder, len(categories), len(ids))
+	}
+	existing := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		existing[category.ID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !existing[id] {
+			return fmt.Errorf("%w: unknown category %s", ErrInvalidCategoryOrder, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: category %s is listed twice", ErrInvalidCategoryOrder, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
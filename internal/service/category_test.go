@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestValidateCategoryOrder(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+	categories := []*model.Category{{ID: first}, {ID: second}}
+
+	tests := []struct {
+		name    string
+		ids     []uuid.UUID
+		wantErr bool
+	}{
+		{"all categories", []uuid.UUID{second, first}, false},
+		{"missing category", []uuid.UUID{second}, true},
+		{"duplicate category", []uuid.UUID{first, first}, true},
+		{"unknown category", []uuid.UUID{first, uuid.New()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCategoryOrder(categories, tt.ids)
+			if tt.wantErr && !errors.Is(err, ErrInvalidCategoryOrder) {
+				t.Errorf("Expected ErrInvalidCategoryOrder, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
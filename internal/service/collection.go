@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrCollectionNotFound        = errors.New("collection not found")
+	ErrInvalidCollection         = errors.New("invalid collection")
+	ErrDuplicateCollectionName   = errors.New("collection name already exists")
+	ErrDefaultCollection         = errors.New("the default collection cannot be deleted")
+	ErrArticleNotInCollection    = errors.New("article is not in the collection")
+	ErrInvalidCollectionPosition = errors.New("invalid collection position")
+)
+
+const (
+	maxCollectionNameLength        = 50
+	maxCollectionDescriptionLength = 1000
+)
+
+type CollectionService struct {
+	repo        *repository.CollectionRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewCollectionService(repo *repository.CollectionRepository, articleRepo *repository.ArticleRepository) *CollectionService {
+	return &CollectionService{repo: repo, articleRepo: articleRepo}
+}
+
+// GetAll returns all collections with their number of articles, the default collection first
+func (s *CollectionService) GetAll(ctx context.Context) ([]*model.Collection, error) {
+	collections, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all collections: %w", err)
+	}
+	return collections, nil
+}
+
+func (s *CollectionService) GetByID(ctx context.Context, id uuid.UUID) (*model.Collection, error) {
+	collection, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection with ID %s: %w", id, err)
+	}
+	return collection, nil
+}
+
+// GetByArticleID returns the collections containing the article
+func (s *CollectionService) GetByArticleID(ctx context.Context, articleID uuid.UUID) ([]*model.Collection, error) {
+	if err := s.checkArticle(ctx, articleID); err != nil {
+		return nil, err
+	}
+
+	collections, err := s.repo.GetByArticleID(ctx, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections of article %s: %w", articleID, err)
+	}
+	return collections, nil
+}
+
+func (s *CollectionService) Create(ctx context.Context, req *model.CreateCollectionRequest) (*model.Collection, error) {
+	name, description, err := s.validateCollection(ctx, req.Name, req.Description, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	collection := &model.Collection{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	createdCollection, err := s.repo.Create(ctx, collection)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateCollectionName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return createdCollection, nil
+}
+
+func (s *CollectionService) Update(ctx context.Context, id uuid.UUID, req *model.UpdateCollectionRequest) (*model.Collection, error) {
+	name, description, err := s.validateCollection(ctx, req.Name, req.Description, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Update(ctx, id, name, description)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateCollectionName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update collection with ID %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrCollectionNotFound
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Delete deletes the collection, its articles are kept. The default collection cannot be deleted.
+func (s *CollectionService) Delete(ctx context.Context, id uuid.UUID) error {
+	collection, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if collection.IsDefault {
+		return ErrDefaultCollection
+	}
+
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection with ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// GetArticles returns a page of the articles of the collection in their order
+func (s *CollectionService) GetArticles(ctx context.Context, id uuid.UUID, page model.PageRequest) (*model.CollectionArticlePage, error) {
+	if page.Offset > 0 || page.IncludeTotal {
+		return nil, fmt.Errorf("%w: collections only support cursor and limit", ErrInvalidPagination)
+	}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	var after *model.CollectionArticle
+	if page.Cursor != "" {
+		var err error
+		after, err = decodeCollectionCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// one more article than requested tells whether there is a next page
+	articles, err := s.repo.GetArticles(ctx, id, after, page.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles of collection %s: %w", id, err)
+	}
+	result := &model.CollectionArticlePage{Articles: articles}
+	if len(articles) > page.Limit {
+		result.Articles = articles[:page.Limit]
+		result.NextCursor = encodeCollectionCursor(result.Articles[page.Limit-1])
+	}
+	return result, nil
+}
+
+// AddArticle adds the article at the position of the collection, or at the end if position is nil.
+// Adding to the default collection saves the article.
+func (s *CollectionService) AddArticle(ctx context.Context, id, articleID uuid.UUID, position *int) error {
+	if err := validateCollectionPosition(position); err != nil {
+		return err
+	}
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.checkArticle(ctx, articleID); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddArticle(ctx, id, articleID, position); err != nil {
+		return fmt.Errorf("failed to add article %s to collection %s: %w", articleID, id, err)
+	}
+	return nil
+}
+
+// RemoveArticle removes the article from the collection. Removing it from the default collection unsaves it.
+func (s *CollectionService) RemoveArticle(ctx context.Context, id, articleID uuid.UUID) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	found, err := s.repo.RemoveArticle(ctx, id, articleID)
+	if err != nil {
+		return fmt.Errorf("failed to remove article %s from collection %s: %w", articleID, id, err)
+	}
+	if !found {
+		return ErrArticleNotInCollection
+	}
+	return nil
+}
+
+// MoveArticle moves the article to another position of the collection or to another collection
+func (s *CollectionService) MoveArticle(ctx context.Context, id, articleID uuid.UUID, req *model.MoveCollectionArticleRequest) error {
+	if err := validateCollectionPosition(req.Position); err != nil {
+		return err
+	}
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	targetID := id
+	if req.CollectionID != nil {
+		targetID = *req.CollectionID
+		if _, err := s.GetByID(ctx, targetID); err != nil {
+			return err
+		}
+	}
+
+	found, err := s.repo.MoveArticle(ctx, id, articleID, targetID, req.Position)
+	if err != nil {
+		return fmt.Errorf("failed to move article %s of collection %s: %w", articleID, id, err)
+	}
+	if !found {
+		return ErrArticleNotInCollection
+	}
+	return nil
+}
+
+func (s *CollectionService) checkArticle(ctx context.Context, articleID uuid.UUID) error {
+	_, err := s.articleRepo.GetByID(ctx, articleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get article with ID %s: %w", articleID, err)
+	}
+	return nil
+}
+
+// validateCollection returns the trimmed name and description if they are valid and the name is not used
+// by a collection other than excludeID
+func (s *CollectionService) validateCollection(ctx context.Context, name, description string, excludeID *uuid.UUID) (string, string, error) {
+	name = normalizeName(name)
+	description = strings.TrimSpace(description)
+	if name == "" {
+		return "", "", fmt.Errorf("%w: name cannot be empty", ErrInvalidCollection)
+	}
+	if utf8.RuneCountInString(name) > maxCollectionNameLength {
+		return "", "", fmt.Errorf("%w: name cannot be longer than %d characters", ErrInvalidCollection, maxCollectionNameLength)
+	}
+	if utf8.RuneCountInString(description) > maxCollectionDescriptionLength {
+		return "", "", fmt.Errorf("%w: description cannot be longer than %d characters", ErrInvalidCollection, maxCollectionDescriptionLength)
+	}
+
+	exists, err := s.repo.IsNameExists(ctx, name, excludeID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check for duplicate collection name: %w", err)
+	}
+	if exists {
+		return "", "", ErrDuplicateCollectionName
+	}
+	return name, description, nil
+}
+
+func validateCollectionPosition(position *int) error {
+	if position != nil && *position < 0 {
+		return fmt.Errorf("%w: position cannot be negative", ErrInvalidCollectionPosition)
+	}
+	return nil
+}
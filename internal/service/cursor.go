@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+// encodeArticleCursor returns the opaque representation of the cursor
+func encodeArticleCursor(cursor model.ArticleCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeArticleCursor parses a cursor returned by encodeArticleCursor. It must belong to a page of the given sort.
+func decodeArticleCursor(s string, sort model.ArticleSort) (*model.ArticleCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	var cursor model.ArticleCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	if cursor.Sort != sort {
+		return nil, fmt.Errorf("%w: the cursor belongs to the sort %q", ErrInvalidPagination, cursor.Sort)
+	}
+	return &cursor, nil
+}
+
+// articleCursorAfter returns the cursor positioned after the article
+func articleCursorAfter(article *model.Article, sort model.ArticleSort) model.ArticleCursor {
+	return model.ArticleCursor{
+		Sort:        sort,
+		PublishedAt: article.PublishedAt,
+		LastReadAt:  article.LastReadAt,
+		ReadingTime: article.ReadingTime,
+		Priority:    article.Priority,
+		ID:          article.ID,
+	}
+}
+
+// collectionCursor is the position of the last article of a collection page
+type collectionCursor struct {
+	Position  int       `json:"p"`
+	ArticleID uuid.UUID `json:"i"`
+}
+
+// encodeCollectionCursor returns the opaque cursor after the article of a collection
+func encodeCollectionCursor(article *model.CollectionArticle) string {
+	data, _ := json.Marshal(collectionCursor{Position: article.Position, ArticleID: article.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCollectionCursor parses a cursor returned by encodeCollectionCursor
+func decodeCollectionCursor(s string) (*model.CollectionArticle, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	var cursor collectionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ArticleID == uuid.Nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	return &model.CollectionArticle{Article: model.Article{ID: cursor.ArticleID}, Position: cursor.Position}, nil
+}
+
+// trashCursor is the position of the last article of a trash page
+type trashCursor struct {
+	DeletedAt time.Time `json:"d"`
+	ArticleID uuid.UUID `json:"i"`
+}
+
+// encodeTrashCursor returns the opaque cursor after the trashed article
+func encodeTrashCursor(article *model.Article) string {
+	data, _ := json.Marshal(trashCursor{DeletedAt: *article.DeletedAt, ArticleID: article.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeTrashCursor parses a cursor returned by encodeTrashCursor
+func decodeTrashCursor(s string) (*model.Article, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	var cursor trashCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ArticleID == uuid.Nil || cursor.DeletedAt.IsZero() {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPagination)
+	}
+	return &model.Article{ID: cursor.ArticleID, DeletedAt: &cursor.DeletedAt}, nil
+}
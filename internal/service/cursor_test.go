@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestArticleCursor_RoundTrip(t *testing.T) {
+	article := &model.Article{
+		ID:          uuid.New(),
+		PublishedAt: time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC),
+		ReadingTime: 7,
+	}
+	encoded := encodeArticleCursor(articleCursorAfter(article, model.ArticleSortShortest))
+
+	cursor, err := decodeArticleCursor(encoded, model.ArticleSortShortest)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cursor.ID != article.ID || cursor.ReadingTime != 7 || !cursor.PublishedAt.Equal(article.PublishedAt) {
+		t.Errorf("Expected the sort keys of the article, got %+v", cursor)
+	}
+}
+
+func TestArticleCursor_Invalid(t *testing.T) {
+	encoded := encodeArticleCursor(model.ArticleCursor{Sort: model.ArticleSortNewest, ID: uuid.New()})
+
+	for name, tt := range map[string]struct {
+		cursor string
+		sort   model.ArticleSort
+	}{
+		"malformed":  {cursor: "not a cursor!", sort: model.ArticleSortNewest},
+		"other sort": {cursor: encoded, sort: model.ArticleSortOldest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := decodeArticleCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidPagination) {
+				t.Errorf("Expected ErrInvalidPagination, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCollectionCursor_RoundTrip(t *testing.T) {
+	article := &model.CollectionArticle{Article: model.Article{ID: uuid.New()}, Position: 12}
+
+	cursor, err := decodeCollectionCursor(encodeCollectionCursor(article))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cursor.ID != article.ID || cursor.Position != 12 {
+		t.Errorf("Expected position and ID of the article, got %+v", cursor)
+	}
+
+	if _, err := decodeCollectionCursor("not a cursor!"); !errors.Is(err, ErrInvalidPagination) {
+		t.Errorf("Expected ErrInvalidPagination, got %v", err)
+	}
+}
+
+func TestTrashCursor_RoundTrip(t *testing.T) {
+	deletedAt := time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC)
+	article := &model.Article{ID: uuid.New(), DeletedAt: &deletedAt}
+
+	cursor, err := decodeTrashCursor(encodeTrashCursor(article))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cursor.ID != article.ID || !cursor.DeletedAt.Equal(deletedAt) {
+		t.Errorf("Expected deletion time and ID of the article, got %+v", cursor)
+	}
+
+	if _, err := decodeTrashCursor(encodeCollectionCursor(&model.CollectionArticle{Article: *article})); !errors.Is(err, ErrInvalidPagination) {
+		t.Errorf("Expected ErrInvalidPagination for a collection cursor, got %v", err)
+	}
+}
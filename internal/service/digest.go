@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"golang.org/x/net/html"
+)
+
+var ErrEmptyDigest = errors.New("no articles for the digest")
+
+const (
+	// maxDigestArticles is the maximum number of articles in a digest, further articles are left out
+	maxDigestArticles = 200
+	// digestPeriod is the period of the unread articles in a digest without selection, the interval of the daily digest
+	digestPeriod = 24 * time.Hour
+	// maxDigestImages is the maximum number of images embedded in a digest, further images are left out
+	maxDigestImages    = 100
+	maxDigestImageSize = 2 << 20
+	digestImageWorkers = 4
+	// digestImageTimeout bounds the time spent on downloading all images of a digest
+	digestImageTimeout = 30 * time.Second
+)
+
+// digestImageTypes maps the embedded image types to their file extensions, SVG is left out as it can contain scripts
+var digestImageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// DigestService bundles articles as EPUB books for offline reading
+type DigestService struct {
+	articleService    *ArticleService
+	collectionService *CollectionService
+	client            *http.Client
+}
+
+func NewDigestService(articleService *ArticleService, collectionService *CollectionService) *DigestService {
+	return &DigestService{
+		articleService:    articleService,
+		collectionService: collectionService,
+		client:            &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Digest is a built EPUB book, its images are already downloaded
+type Digest struct {
+	Title string
+	date  time.Time
+	book  *epubBook
+}
+
+func (d *Digest) ContentType() string {
+	return epubMediaType
+}
+
+// FileName returns the name of the digest file, dated with the day it was built
+func (d *Digest) FileName() string {
+	return fmt.Sprintf("digest-%s.epub", d.date.Format(time.DateOnly))
+}
+
+func (d *Digest) Write(w io.Writer) error {
+	if err := writeEpub(w, d.book); err != nil {
+		return fmt.Errorf("failed to write digest: %w", err)
+	}
+	return nil
+}
+
+// Build selects the articles and bundles them in a book with a chapter per article, grouped by feed.
+// At most maxDigestArticles articles are included, images that cannot be downloaded are left out.
+func (s *DigestService) Build(ctx context.Context, selection model.DigestSelection, now time.Time) (*Digest, error) {
+	title, articles, err := s.selectArticles(ctx, selection, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, ErrEmptyDigest
+	}
+	if err := s.articleService.attachFeeds(ctx, articles); err != nil {
+		return nil, err
+	}
+	return &Digest{Title: title, date: now, book: s.buildBook(ctx, title, articles, now)}, nil
+}
+
+// WriteDaily builds the digest of the unread articles of the last 24 hours and writes it to the directory.
+// The file is renamed into place once complete, so readers never see a partial digest.
+func (s *DigestService) WriteDaily(ctx context.Context, dir string, now time.Time) (string, error) {
+	digest, err := s.Build(ctx, model.DigestSelection{}, now)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create digest directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, ".digest-*.epub")
+	if err != nil {
+		return "", fmt.Errorf("failed to create digest file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if err := digest.Write(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write digest: %w", err)
+	}
+
+	path := filepath.Join(dir, digest.FileName())
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write digest: %w", err)
+	}
+	return path, nil
+}
+
+// selectArticles returns the title of the digest and its articles in reading order
+func (s *DigestService) selectArticles(ctx context.Context, selection model.DigestSelection, now time.Time) (string, []*model.Article, error) {
+	if selection.CollectionID != nil {
+		return s.selectCollection(ctx, *selection.CollectionID)
+	}
+
+	title := "Articles " + now.Format(time.DateOnly)
+	var filter model.ArticleFilter
+	if selection.Filter != nil {
+		filter = *selection.Filter
+		if err := validateArticleFilter(filter); err != nil {
+			return "", nil, err
+		}
+	} else {
+		title = "Digest " + now.Format(time.DateOnly)
+		unread := false
+		from := now.Add(-digestPeriod)
+		filter = model.ArticleFilter{Read: &unread, PublishedFrom: &from}
+	}
+	if filter.Sort == "" {
+		filter.Sort = model.ArticleSortOldest
+	}
+
+	articles, err := s.articleService.repo.GetPage(ctx, restrictToSortable(filter), nil, 0, maxDigestArticles)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+	return title, articles, nil
+}
+
+func (s *DigestService) selectCollection(ctx context.Context, id uuid.UUID) (string, []*model.Article, error) {
+	collection, err := s.collectionService.GetByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	page, err := s.collectionService.GetArticles(ctx, id, model.PageRequest{Limit: maxDigestArticles})
+	if err != nil {
+		return "", nil, err
+	}
+	articles := make([]*model.Article, len(page.Articles))
+	for i, article := range page.Articles {
+		articles[i] = &article.Article
+	}
+	return collection.Name, articles, nil
+}
+
+// buildBook groups the articles by feed in order of their first article and embeds the images of their content
+func (s *DigestService) buildBook(ctx context.Context, title string, articles []*model.Article, now time.Time) *epubBook {
+	book := &epubBook{ID: uuid.New(), Title: title, Language: digestLanguage(articles), Modified: now}
+
+	type parsedArticle struct {
+		article *model.Article
+		base    *url.URL
+		nodes   []*html.Node
+	}
+	parsed := make([]parsedArticle, len(articles))
+	var imageURLs []string
+	for i, article := range articles {
+		content := article.Content
+		if strings.TrimSpace(content) == "" {
+			content = article.Description
+		}
+		base, _ := url.Parse(article.SourceUrl)
+		nodes := parseArticleHTML(content)
+		parsed[i] = parsedArticle{article: article, base: base, nodes: nodes}
+		imageURLs = append(imageURLs, sanitizedImageURLs(nodes, base)...)
+	}
+
+	images := s.fetchImages(ctx, imageURLs)
+	paths := make(map[string]string, len(images))
+	for _, imageURL := range uniqueValues(imageURLs) {
+		if image := images[imageURL]; image != nil {
+			book.Images = append(book.Images, image)
+			paths[imageURL] = "../" + image.FileName
+		}
+	}
+
+	sections := make(map[string]*epubSection)
+	for i, p := range parsed {
+		sectionTitle := "Other articles"
+		if p.article.Feed != nil {
+			sectionTitle = p.article.Feed.Name
+		}
+		section := sections[sectionTitle]
+		if section == nil {
+			section = &epubSection{Title: sectionTitle}
+			sections[sectionTitle] = section
+			book.Sections = append(book.Sections, section)
+		}
+
+		language := p.article.Language
+		if language == "" {
+			language = book.Language
+		}
+		section.Chapters = append(section.Chapters, &epubChapter{
+			FileName: fmt.Sprintf("articles/article-%d.xhtml", i+1),
+			Title:    p.article.Title,
+			Meta:     digestArticleMeta(p.article),
+			URL:      resolveSanitizedURL(p.article.SourceUrl, nil, false),
+			Language: language,
+			Body:     renderSanitizedXHTML(p.nodes, p.base, paths),
+		})
+	}
+	return book
+}
+
+// fetchImages downloads the images in parallel, failed downloads are missing in the result
+func (s *DigestService) fetchImages(ctx context.Context, imageURLs []string) map[string]*epubImage {
+	urls := uniqueValues(imageURLs)
+	if len(urls) > maxDigestImages {
+		urls = urls[:maxDigestImages]
+	}
+	ctx, cancel := context.WithTimeout(ctx, digestImageTimeout)
+	defer cancel()
+
+	images := make(map[string]*epubImage, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, digestImageWorkers)
+	for i, imageURL := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			image, err := s.fetchImage(ctx, imageURL)
+			if err != nil {
+				fmt.Printf("Failed to embed image %s: %v\n", imageURL, err)
+				return
+			}
+			image.FileName = fmt.Sprintf("images/image-%d.%s", i+1, digestImageTypes[image.MediaType])
+			mu.Lock()
+			images[imageURL] = image
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return images
+}
+
+func (s *DigestService) fetchImage(ctx context.Context, imageURL string) (*epubImage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDigestImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDigestImageSize {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxDigestImageSize)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if _, ok := digestImageTypes[mediaType]; !ok {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if _, ok := digestImageTypes[mediaType]; !ok {
+		return nil, fmt.Errorf("unsupported image type %q", mediaType)
+	}
+	return &epubImage{MediaType: mediaType, Data: data}, nil
+}
+
+// digestLanguage returns the most common language of the articles, English if none is known
+func digestLanguage(articles []*model.Article) string {
+	counts := make(map[string]int)
+	language := "en"
+	for _, article := range articles {
+		if article.Language == "" {
+			continue
+		}
+		counts[article.Language]++
+		if counts[article.Language] > counts[language] {
+			language = article.Language
+		}
+	}
+	return language
+}
+
+// digestArticleMeta returns the author and the publication date of the article
+func digestArticleMeta(article *model.Article) string {
+	parts := make([]string, 0, 2)
+	if author := strings.TrimSpace(article.Author); author != "" {
+		parts = append(parts, author)
+	}
+	if !article.PublishedAt.IsZero() {
+		parts = append(parts, article.PublishedAt.Format("January 2, 2006"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// uniqueValues returns the values without duplicates in the order of their first occurrence
+func uniqueValues[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	unique := make([]T, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func digestTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pixel.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngData.Bytes())
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDigestService_BuildBook(t *testing.T) {
+	server := digestTestServer(t)
+	s := &DigestService{client: server.Client()}
+	news := &model.ArticleFeed{Name: "News"}
+	articles := []*model.Article{
+		{Title: "First", SourceUrl: server.URL + "/first", Language: "de", Feed: news,
+			Content: `<p>Hello <img src="/pixel.png" alt="pixel"><img src="/missing.png" alt="gone"></p>`},
+		{Title: "Untitled & <odd>", SourceUrl: "javascript:alert(1)", Description: "<p>Only a summary</p>"},
+		{Title: "Second", SourceUrl: server.URL + "/second", Language: "de", Feed: news,
+			Content: `<img src="/pixel.png"><img src="/page.html" alt="page">`},
+	}
+
+	book := s.buildBook(context.Background(), "Digest", articles, time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC))
+
+	if book.Language != "de" {
+		t.Errorf("Expected language de, got %s", book.Language)
+	}
+	if len(book.Sections) != 2 || book.Sections[0].Title != "News" || book.Sections[1].Title != "Other articles" {
+		t.Fatalf("Expected sections News and Other articles, got %+v", book.Sections)
+	}
+	if len(book.Sections[0].Chapters) != 2 || book.Sections[0].Chapters[1].Title != "Second" {
+		t.Errorf("Expected both News articles in the first section, got %+v", book.Sections[0].Chapters)
+	}
+	if len(book.Images) != 1 || book.Images[0].MediaType != "image/png" {
+		t.Fatalf("Expected the PNG to be embedded once, got %+v", book.Images)
+	}
+	first := book.Sections[0].Chapters[0].Body
+	want := `<p>Hello <img src="../` + book.Images[0].FileName + `" alt="pixel"/>gone</p>`
+	if first != want {
+		t.Errorf("Expected body %q, got %q", want, first)
+	}
+	if chapter := book.Sections[1].Chapters[0]; chapter.URL != "" || chapter.Body != "<p>Only a summary</p>" {
+		t.Errorf("Expected the description without link, got %+v", chapter)
+	}
+}
+
+func TestWriteEpub(t *testing.T) {
+	server := digestTestServer(t)
+	s := &DigestService{client: server.Client()}
+	articles := []*model.Article{
+		{Title: "A & B", SourceUrl: server.URL + "/a", Feed: &model.ArticleFeed{Name: "Feed <1>"},
+			Author: "Jane", PublishedAt: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC),
+			Content: `<p>Text<img src="/pixel.png"></p><div><p>Nested</div>`},
+	}
+	book := s.buildBook(context.Background(), "Digest", articles, time.Now())
+
+	var b bytes.Buffer
+	if err := writeEpub(&b, book); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip file, got %v", err)
+	}
+
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Errorf("Expected an uncompressed mimetype first, got %s with method %d", first.Name, first.Method)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("Expected the EPUB mimetype, got %q", files["mimetype"])
+	}
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/articles/article-1.xhtml"} {
+		content, ok := files[name]
+		if !ok {
+			t.Errorf("Expected %s in the book", name)
+			continue
+		}
+		decoder := xml.NewDecoder(strings.NewReader(content))
+		for {
+			if _, err := decoder.Token(); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Errorf("Expected %s to be well-formed XML, got %v", name, err)
+				break
+			}
+		}
+	}
+	if _, ok := files["OEBPS/"+book.Images[0].FileName]; !ok {
+		t.Errorf("Expected the image in the book")
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `href="`+book.Images[0].FileName+`" media-type="image/png"`) {
+		t.Errorf("Expected the image in the manifest, got %s", files["OEBPS/content.opf"])
+	}
+	if !strings.Contains(files["OEBPS/nav.xhtml"], `<span>Feed &lt;1&gt;</span>`) {
+		t.Errorf("Expected the feed in the table of contents, got %s", files["OEBPS/nav.xhtml"])
+	}
+}
+
+func TestDigestLanguage(t *testing.T) {
+	tests := []struct {
+		languages []string
+		want      string
+	}{
+		{nil, "en"},
+		{[]string{"", ""}, "en"},
+		{[]string{"fr", "de", "de", ""}, "de"},
+	}
+	for _, tt := range tests {
+		var articles []*model.Article
+		for _, language := range tt.languages {
+			articles = append(articles, &model.Article{Language: language})
+		}
+		if got := digestLanguage(articles); got != tt.want {
+			t.Errorf("digestLanguage(%v) = %s, want %s", tt.languages, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const epubMediaType = "application/epub+zip"
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const epubStyle = `body { font-family: serif; line-height: 1.5; }
+h1 { font-size: 1.4em; }
+.meta { color: #666; font-size: 0.85em; }
+img { max-width: 100%; height: auto; }
+pre { white-space: pre-wrap; }`
+
+// epubBook is an EPUB 3 book of articles, with a chapter per article and the chapters grouped in sections by feed
+type epubBook struct {
+	ID       uuid.UUID
+	Title    string
+	Language string
+	Modified time.Time
+	Sections []*epubSection
+	Images   []*epubImage
+}
+
+type epubSection struct {
+	Title    string
+	Chapters []*epubChapter
+}
+
+// epubChapter is an article, Body is its sanitized XHTML content. Images are referenced by their path in the book.
+type epubChapter struct {
+	FileName string
+	Title    string
+	Meta     string
+	URL      string
+	Language string
+	Body     string
+}
+
+type epubFile struct {
+	name string
+	data []byte
+}
+
+type epubImage struct {
+	FileName  string
+	MediaType string
+	Data      []byte
+}
+
+// writeEpub writes the book as an EPUB 3 container. The mimetype comes first and is stored uncompressed,
+// as required for readers to recognize the file.
+func writeEpub(w io.Writer, book *epubBook) error {
+	zw := zip.NewWriter(w)
+
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, epubMediaType); err != nil {
+		return err
+	}
+
+	files := []*epubFile{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", []byte(renderEpubPackage(book))},
+		{"OEBPS/nav.xhtml", []byte(renderEpubNav(book))},
+		{"OEBPS/style.css", []byte(epubStyle)},
+	}
+	for _, chapter := range epubChapters(book) {
+		files = append(files, &epubFile{"OEBPS/" + chapter.FileName, []byte(renderEpubChapter(chapter))})
+	}
+	for _, image := range book.Images {
+		files = append(files, &epubFile{"OEBPS/" + image.FileName, image.Data})
+	}
+	for _, file := range files {
+		if err := writeZipFile(zw, file.name, file.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func renderEpubPackage(book *epubBook) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + escapeXML(book.Language) + `">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">urn:uuid:` + book.ID.String() + `</dc:identifier>
+    <dc:title>` + escapeXML(book.Title) + `</dc:title>
+    <dc:language>` + escapeXML(book.Language) + `</dc:language>
+    <dc:creator>Fyrss</dc:creator>
+    <meta property="dcterms:modified">` + book.Modified.UTC().Format("2006-01-02T15:04:05Z") + `</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+`)
+	var spine strings.Builder
+	spine.WriteString(`    <itemref idref="nav"/>` + "\n")
+	for i, chapter := range epubChapters(book) {
+		id := fmt.Sprintf("chapter-%d", i+1)
+		b.WriteString(`    <item id="` + id + `" href="` + chapter.FileName + `" media-type="application/xhtml+xml"/>` + "\n")
+		spine.WriteString(`    <itemref idref="` + id + `"/>` + "\n")
+	}
+	for i, image := range book.Images {
+		b.WriteString(fmt.Sprintf(`    <item id="image-%d" href="%s" media-type="%s"/>`+"\n", i+1, image.FileName, image.MediaType))
+	}
+	b.WriteString("  </manifest>\n  <spine>\n")
+	b.WriteString(spine.String())
+	b.WriteString("  </spine>\n</package>")
+	return b.String()
+}
+
+// renderEpubNav renders the table of contents, a list of the feeds with the articles of each feed
+func renderEpubNav(book *epubBook) string {
+	var b strings.Builder
+	b.WriteString(epubXHTMLHeader(book.Language, book.Title, "style.css"))
+	b.WriteString(`<nav epub:type="toc" id="toc">
+<h1>` + escapeXML(book.Title) + `</h1>
+<ol>
+`)
+	for _, section := range book.Sections {
+		b.WriteString("<li><span>" + escapeXML(section.Title) + "</span>\n<ol>\n")
+		for _, chapter := range section.Chapters {
+			b.WriteString(`<li><a href="` + chapter.FileName + `">` + escapeXML(chapter.Title) + "</a></li>\n")
+		}
+		b.WriteString("</ol>\n</li>\n")
+	}
+	b.WriteString("</ol>\n</nav>\n</body>\n</html>")
+	return b.String()
+}
+
+func renderEpubChapter(chapter *epubChapter) string {
+	var b strings.Builder
+	b.WriteString(epubXHTMLHeader(chapter.Language, chapter.Title, "../style.css"))
+	b.WriteString("<article>\n<h1>" + escapeXML(chapter.Title) + "</h1>\n")
+	b.WriteString(`<p class="meta">` + escapeXML(chapter.Meta))
+	if chapter.URL != "" {
+		if chapter.Meta != "" {
+			b.WriteString(" · ")
+		}
+		b.WriteString(`<a href="` + escapeXML(chapter.URL) + `">Original article</a>`)
+	}
+	b.WriteString("</p>\n")
+	b.WriteString(chapter.Body)
+	b.WriteString("\n</article>\n</body>\n</html>")
+	return b.String()
+}
+
+func epubXHTMLHeader(language, title, stylesheet string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="` + escapeXML(language) + `" lang="` + escapeXML(language) + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + escapeXML(title) + `</title>
+<link rel="stylesheet" type="text/css" href="` + stylesheet + `"/>
+</head>
+<body>
+`
+}
+
+// epubChapters returns the chapters of all sections in reading order
+func epubChapters(book *epubBook) []*epubChapter {
+	var chapters []*epubChapter
+	for _, section := range book.Sections {
+		chapters = append(chapters, section.Chapters...)
+	}
+	return chapters
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+var ErrInvalidExportFormat = errors.New("invalid export format")
+
+// exportBatchSize is the number of articles loaded at once while an export is streamed
+const exportBatchSize = 200
+
+var exportContentTypes = map[model.ExportFormat]string{
+	model.ExportFormatJSON:     "application/json",
+	model.ExportFormatCSV:      "text/csv; charset=utf-8",
+	model.ExportFormatMarkdown: "text/markdown; charset=utf-8",
+	model.ExportFormatHTML:     "text/html; charset=utf-8",
+}
+
+// ArticleExport is a validated export of the saved articles matching a filter, which is streamed by Write
+type ArticleExport struct {
+	svc    *ArticleService
+	format model.ExportFormat
+	filter model.ArticleFilter
+}
+
+// NewExport validates the format and the filter of an export of saved articles, newest first by default
+func (s *ArticleService) NewExport(format model.ExportFormat, filter model.ArticleFilter) (*ArticleExport, error) {
+	if _, ok := exportContentTypes[format]; !ok {
+		return nil, fmt.Errorf("%w: %q, use json, csv, md or html", ErrInvalidExportFormat, format)
+	}
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Saved != nil && !*filter.Saved {
+		return nil, fmt.Errorf("%w: saved articles cannot be filtered by saved=false", ErrInvalidArticleFilter)
+	}
+
+	saved := true
+	filter.Saved = &saved
+	if filter.Sort == "" {
+		filter.Sort = model.ArticleSortNewest
+	}
+	return &ArticleExport{svc: s, format: format, filter: restrictToSortable(filter)}, nil
+}
+
+func (e *ArticleExport) ContentType() string {
+	return exportContentTypes[e.format]
+}
+
+// FileName returns the name of the export file, dated with the given day
+func (e *ArticleExport) FileName(now time.Time) string {
+	return fmt.Sprintf("articles-%s.%s", now.Format(time.DateOnly), e.format)
+}
+
+// Write streams the articles to w in batches, so the export is never held in memory as a whole.
+// If it fails after the first batch, w has already received a part of the export.
+func (e *ArticleExport) Write(ctx context.Context, w io.Writer) error {
+	writer := newArticleWriter(e.format, w)
+	if err := writer.begin(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	var cursor *model.ArticleCursor
+	for {
+		articles, err := e.svc.repo.GetPage(ctx, e.filter, cursor, 0, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get articles: %w", err)
+		}
+		if err := e.svc.attachTags(ctx, articles); err != nil {
+			return err
+		}
+		if err := e.svc.attachFeeds(ctx, articles); err != nil {
+			return err
+		}
+		for _, article := range articles {
+			if err := writer.write(article); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+		}
+		if len(articles) < exportBatchSize {
+			break
+		}
+		next := articleCursorAfter(articles[len(articles)-1], e.filter.Sort)
+		cursor = &next
+	}
+
+	if err := writer.end(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+// articleWriter writes the articles of an export one by one in its format
+type articleWriter interface {
+	begin() error
+	write(article *model.Article) error
+	end() error
+}
+
+func newArticleWriter(format model.ExportFormat, w io.Writer) articleWriter {
+	switch format {
+	case model.ExportFormatCSV:
+		return &csvArticleWriter{w: csv.NewWriter(w)}
+	case model.ExportFormatMarkdown:
+		return &markdownArticleWriter{w: w}
+	case model.ExportFormatHTML:
+		return &htmlArticleWriter{w: w}
+	default:
+		return &jsonArticleWriter{w: w}
+	}
+}
+
+// jsonArticleWriter writes a JSON array of the articles with their tags and feed
+type jsonArticleWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonArticleWriter) begin() error {
+	_, err := io.WriteString(j.w, "[")
+	return err
+}
+
+func (j *jsonArticleWriter) write(article *model.Article) error {
+	data, err := json.Marshal(article)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonArticleWriter) end() error {
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
+
+// csvArticleWriter writes one row per article, the description as plain text and the tags separated by semicolons
+type csvArticleWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvArticleWriter) begin() error {
+	return c.w.Write([]string{"id", "title", "url", "feed", "author", "published_at", "read_at", "language", "reading_time", "tags", "note", "description"})
+}
+
+func (c *csvArticleWriter) write(article *model.Article) error {
+	err := c.w.Write([]string{
+		article.ID.String(),
+		article.Title,
+		article.SourceUrl,
+		exportFeedName(article),
+		article.Author,
+		article.PublishedAt.Format(time.RFC3339),
+		exportOptionalTime(article.LastReadAt),
+		article.Language,
+		strconv.Itoa(article.ReadingTime),
+		strings.Join(exportTagNames(article), "; "),
+		article.Note,
+		plainText(article.Description),
+	})
+	if err != nil {
+		return err
+	}
+	// flush every row, the csv.Writer buffers on top of w
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvArticleWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// markdownArticleWriter writes one document per article, each starting with its front matter
+type markdownArticleWriter struct {
+	w io.Writer
+}
+
+func (m *markdownArticleWriter) begin() error {
+	return nil
+}
+
+func (m *markdownArticleWriter) write(article *model.Article) error {
+	_, err := io.WriteString(m.w, renderArticleMarkdown(article))
+	return err
+}
+
+func (m *markdownArticleWriter) end() error {
+	return nil
+}
+
+// renderArticleMarkdown renders the front matter with source URL, feed, dates and tags, followed by title,
+// description, content and note of the article
+func renderArticleMarkdown(article *model.Article) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", yamlString(article.Title))
+	fmt.Fprintf(&b, "source: %s\n", yamlString(article.SourceUrl))
+	if feed := exportFeedName(article); feed != "" {
+		fmt.Fprintf(&b, "feed: %s\n", yamlString(feed))
+	}
+	if article.Author != "" {
+		fmt.Fprintf(&b, "author: %s\n", yamlString(article.Author))
+	}
+	fmt.Fprintf(&b, "published: %s\n", article.PublishedAt.Format(time.RFC3339))
+	if article.LastReadAt != nil {
+		fmt.Fprintf(&b, "read: %s\n", article.LastReadAt.Format(time.RFC3339))
+	}
+	if tags := exportTagNames(article); len(tags) > 0 {
+		b.WriteString("tags:\n")
+		for _, tag := range tags {
+			fmt.Fprintf(&b, "  - %s\n", yamlString(tag))
+		}
+	}
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(strings.Fields(article.Title), " "))
+	if description := markdownText(article.Description); description != "" {
+		fmt.Fprintf(&b, "%s\n\n", description)
+	}
+	if content := markdownText(article.Content); content != "" {
+		fmt.Fprintf(&b, "%s\n\n", content)
+	}
+	if article.Note != "" {
+		fmt.Fprintf(&b, "## Note\n\n%s\n\n", article.Note)
+	}
+	return b.String()
+}
+
+// yamlString quotes s as a YAML string. JSON strings are valid YAML.
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// htmlArticleWriter writes an HTML document with one section per article. The text of the articles is
+// escaped, their markup is not copied.
+type htmlArticleWriter struct {
+	w io.Writer
+}
+
+var htmlArticleTemplate = template.Must(template.New("article").Parse(`<article>
+<h2><a href="{{.URL}}">{{.Title}}</a></h2>
+<p><small>{{if .Feed}}{{.Feed}} · {{end}}{{.Published}}{{if .Tags}} · {{.Tags}}{{end}}</small></p>
+{{if .Text}}<p>{{.Text}}</p>
+{{end}}{{if .Note}}<blockquote>{{.Note}}</blockquote>
+{{end}}</article>
+`))
+
+func (h *htmlArticleWriter) begin() error {
+	_, err := io.WriteString(h.w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Saved articles</title>\n</head>\n<body>\n<h1>Saved articles</h1>\n")
+	return err
+}
+
+func (h *htmlArticleWriter) write(article *model.Article) error {
+	return htmlArticleTemplate.Execute(h.w, map[string]string{
+		"URL":       article.SourceUrl,
+		"Title":     article.Title,
+		"Feed":      exportFeedName(article),
+		"Published": article.PublishedAt.Format(time.DateOnly),
+		"Tags":      strings.Join(exportTagNames(article), ", "),
+		"Text":      plainText(article.Description),
+		"Note":      article.Note,
+	})
+}
+
+func (h *htmlArticleWriter) end() error {
+	_, err := io.WriteString(h.w, "</body>\n</html>\n")
+	return err
+}
+
+func exportFeedName(article *model.Article) string {
+	if article.Feed == nil {
+		return ""
+	}
+	return article.Feed.Name
+}
+
+func exportTagNames(article *model.Article) []string {
+	names := make([]string, len(article.Tags))
+	for i, tag := range article.Tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func exportOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func exportTestArticles() []*model.Article {
+	return []*model.Article{
+		{
+			Title:       "Budget \"2024\"",
+			SourceUrl:   "https://example.com/budget",
+			PublishedAt: time.Date(2023, 10, 11, 10, 0, 0, 0, time.UTC),
+			Description: "<p>The budget <b>grows</b>.</p>",
+			Feed:        &model.ArticleFeed{Name: "Example News"},
+			Tags:        []*model.ArticleTag{{Name: "Politics"}, {Name: "Reading list"}},
+		},
+		{
+			Title:       "<script>alert(1)</script>",
+			SourceUrl:   "javascript:alert(1)",
+			PublishedAt: time.Date(2023, 10, 12, 10, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func writeExport(t *testing.T, format model.ExportFormat, articles []*model.Article) string {
+	t.Helper()
+	var b bytes.Buffer
+	writer := newArticleWriter(format, &b)
+	if err := writer.begin(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, article := range articles {
+		if err := writer.write(article); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := writer.end(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return b.String()
+}
+
+func TestNewExport_InvalidFormat(t *testing.T) {
+	if _, err := (&ArticleService{}).NewExport("pdf", model.ArticleFilter{}); !errors.Is(err, ErrInvalidExportFormat) {
+		t.Errorf("Expected ErrInvalidExportFormat, got %v", err)
+	}
+}
+
+func TestArticleWriter_JSON(t *testing.T) {
+	for _, articles := range [][]*model.Article{nil, exportTestArticles()} {
+		var decoded []model.Article
+		if err := json.Unmarshal([]byte(writeExport(t, model.ExportFormatJSON, articles)), &decoded); err != nil {
+			t.Fatalf("Expected a JSON array, got %v", err)
+		}
+		if len(decoded) != len(articles) {
+			t.Errorf("Expected %d articles, got %d", len(articles), len(decoded))
+		}
+	}
+}
+
+func TestArticleWriter_CSV(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeExport(t, model.ExportFormatCSV, exportTestArticles())), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two rows, got %q", lines)
+	}
+	if !strings.Contains(lines[1], `"Budget ""2024""",https://example.com/budget,Example News`) ||
+		!strings.Contains(lines[1], "Politics; Reading list") || !strings.HasSuffix(lines[1], "The budget grows.") {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
+
+func TestRenderArticleMarkdown(t *testing.T) {
+	want := `---
+title: "Budget \"2024\""
+source: "https://example.com/budget"
+feed: "Example News"
+published: 2023-10-11T10:00:00Z
+tags:
+  - "Politics"
+  - "Reading list"
+---
+
+# Budget "2024"
+
+The budget grows.
+
+`
+	if got := renderArticleMarkdown(exportTestArticles()[0]); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	// no tags, no front matter entry
+	if got := renderArticleMarkdown(exportTestArticles()[1]); strings.Contains(got, "tags:") || strings.Contains(got, "feed:") {
+		t.Errorf("Expected no tags and feed, got\n%s", got)
+	}
+}
+
+func TestMarkdownText(t *testing.T) {
+	got := markdownText(`<h2>Budget</h2><p>The budget <b>grows</b>,
+	see <a href="https://example.com/budget">the <i>details</i></a>.</p><script>alert(1)</script>
+	<ul><li>Taxes</li><li><a href="javascript:alert(1)">Debt</a></li></ul>Final<br>words`)
+	want := "### Budget\n\nThe budget grows, see [the details](https://example.com/budget).\n\n- Taxes\n\n- Debt\n\nFinal\n\nwords"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestArticleWriter_HTMLEscapes(t *testing.T) {
+	got := writeExport(t, model.ExportFormatHTML, exportTestArticles())
+	if strings.Contains(got, "<script>") || strings.Contains(got, `href="javascript:`) {
+		t.Errorf("Expected escaped title and URL, got\n%s", got)
+	}
+	if !strings.HasPrefix(got, "<!DOCTYPE html>") || !strings.HasSuffix(got, "</html>\n") {
+		t.Errorf("Expected a complete document, got\n%s", got)
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/url"
@@ -11,7 +12,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/lucasg04/fyrss-server/internal/model"
 	"github.com/lucasg04/fyrss-server/internal/repository"
-	"github.com/mmcdole/gofeed"
 )
 
 var (
@@ -21,19 +21,25 @@ var (
 	ErrInvalidFeedName    = errors.New("feed name cannot be empty")
 	ErrInvalidRSSFeed     = errors.New("URL does not return a valid RSS/Atom feed")
 	ErrFeedValidationFail = errors.New("feed validation failed")
+	ErrInvalidFeedWeight  = errors.New("invalid feed weight")
+	ErrFeedEmpty          = errors.New("no articles found in feed")
 )
 
 type FeedService struct {
 	repo           *repository.FeedRepository
-	rssReader      *RssArticleReader
+	sources        *SourceRegistry
 	articleService *ArticleService
+	storyService   *StoryService
+	ruleService    *RuleService
 }
 
-func NewFeedService(repo *repository.FeedRepository, rssReader *RssArticleReader, articleService *ArticleService) *FeedService {
+func NewFeedService(repo *repository.FeedRepository, sources *SourceRegistry, articleService *ArticleService, storyService *StoryService, ruleService *RuleService) *FeedService {
 	return &FeedService{
 		repo:           repo,
-		rssReader:      rssReader,
+		sources:        sources,
 		articleService: articleService,
+		storyService:   storyService,
+		ruleService:    ruleService,
 	}
 }
 
@@ -61,9 +67,13 @@ func (s *FeedService) Create(ctx context.Context, req *model.CreateFeedRequest)
 	if err := s.validateFeedRequest(req.Name, req.URL); err != nil {
 		return nil, err
 	}
+	if err := validateFeedWeight(req.Weight); err != nil {
+		return nil, err
+	}
 
-	// Validate that the URL actually returns a valid RSS feed
-	if err := s.validateRSSFeed(ctx, req.URL); err != nil {
+	// Validate the URL and config with the source of the requested kind
+	sourceKind, err := s.validateSource(ctx, req.SourceKind, req.URL, req.SourceConfig)
+	if err != nil {
 		return nil, err
 	}
 
@@ -76,14 +86,22 @@ func (s *FeedService) Create(ctx context.Context, req *model.CreateFeedRequest)
 		return nil, ErrDuplicateFeedURL
 	}
 
+	weight := 1.0
+	if req.Weight != nil {
+		weight = *req.Weight
+	}
+
 	now := time.Now()
 	feed := &model.Feed{
-		ID:         uuid.New(),
-		Name:       strings.TrimSpace(req.Name),
-		URL:        strings.TrimSpace(req.URL),
-		CreatedAt:  now,
-		UpdatedAt:  now,
-		LastReadAt: now,
+		ID:           uuid.New(),
+		Name:         strings.TrimSpace(req.Name),
+		URL:          strings.TrimSpace(req.URL),
+		SourceKind:   sourceKind,
+		SourceConfig: sourceConfigOrEmpty(req.SourceConfig),
+		Weight:       weight,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		LastReadAt:   now,
 	}
 
 	createdFeed, err := s.repo.Create(ctx, feed)
@@ -105,9 +123,13 @@ func (s *FeedService) Update(ctx context.Context, id uuid.UUID, req *model.Updat
 	if err := s.validateFeedRequest(req.Name, req.URL); err != nil {
 		return nil, err
 	}
+	if err := validateFeedWeight(req.Weight); err != nil {
+		return nil, err
+	}
 
-	// Validate that the URL actually returns a valid RSS feed
-	if err := s.validateRSSFeed(ctx, req.URL); err != nil {
+	// Validate the URL and config with the source of the requested kind
+	sourceKind, err := s.validateSource(ctx, req.SourceKind, req.URL, req.SourceConfig)
+	if err != nil {
 		return nil, err
 	}
 
@@ -120,9 +142,23 @@ func (s *FeedService) Update(ctx context.Context, id uuid.UUID, req *model.Updat
 		return nil, ErrDuplicateFeedURL
 	}
 
+	existing, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed with ID %s: %w", id, err)
+	}
+
 	feed := &model.Feed{
-		Name: strings.TrimSpace(req.Name),
-		URL:  strings.TrimSpace(req.URL),
+		Name:         strings.TrimSpace(req.Name),
+		URL:          strings.TrimSpace(req.URL),
+		SourceKind:   sourceKind,
+		SourceConfig: sourceConfigOrEmpty(req.SourceConfig),
+		Weight:       existing.Weight,
+	}
+	if req.Weight != nil {
+		feed.Weight = *req.Weight
 	}
 
 	updatedFeed, err := s.repo.Update(ctx, id, feed)
@@ -187,6 +223,17 @@ func (s *FeedService) ValidateFeedURL(ctx context.Context, feedURL string) error
 	return s.validateRSSFeed(ctx, feedURL)
 }
 
+// maxFeedWeight is the largest explicit feed weight
+const maxFeedWeight = 5.0
+
+// validateFeedWeight checks the optional weight of a feed request
+func validateFeedWeight(weight *float64) error {
+	if weight != nil && (*weight < 0 || *weight > maxFeedWeight) {
+		return fmt.Errorf("%w: must be between 0 and %g", ErrInvalidFeedWeight, maxFeedWeight)
+	}
+	return nil
+}
+
 func (s *FeedService) validateFeedRequest(name, feedURL string) error {
 	// Validate name
 	if strings.TrimSpace(name) == "" {
@@ -216,37 +263,27 @@ func (s *FeedService) validateFeedRequest(name, feedURL string) error {
 
 // validateRSSFeed checks if the given URL returns a valid RSS/Atom feed
 func (s *FeedService) validateRSSFeed(ctx context.Context, feedURL string) error {
-	// Create a context with timeout for the RSS validation
-	validateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	// Use gofeed parser to attempt to parse the feed
-	fp := gofeed.NewParser()
-	fp.UserAgent = "Fyrss-Server/1.0 (+https://github.com/LucasG04/fyrss-server)"
+	return validateRSSFeedURL(ctx, feedURL)
+}
 
-	// Parse the feed URL with context
-	feed, err := fp.ParseURLWithContext(feedURL, validateCtx)
+// validateSource resolves the source of the given kind and lets it validate the URL and config.
+// It returns the normalized source kind.
+func (s *FeedService) validateSource(ctx context.Context, kind, feedURL string, config model.SourceConfig) (string, error) {
+	source, err := s.sources.Get(kind)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidRSSFeed, err)
-	}
-
-	// Check if we got a valid feed response
-	if feed == nil {
-		return fmt.Errorf("%w: feed is empty", ErrInvalidRSSFeed)
+		return "", err
 	}
-
-	// Check if the feed has a title (basic requirement for valid feeds)
-	if strings.TrimSpace(feed.Title) == "" {
-		return fmt.Errorf("%w: feed has no title", ErrInvalidRSSFeed)
+	if err := source.Validate(ctx, feedURL, config); err != nil {
+		return "", err
 	}
+	return source.Kind(), nil
+}
 
-	// Additional validation: ensure the feed has at least basic structure
-	// We don't require items as some feeds might be empty but still valid
-	if feed.FeedType == "" {
-		return fmt.Errorf("%w: unable to determine feed type", ErrInvalidRSSFeed)
+func sourceConfigOrEmpty(config model.SourceConfig) model.SourceConfig {
+	if config == nil {
+		return model.SourceConfig{}
 	}
-
-	return nil
+	return config
 }
 
 // processFeedAsync automatically processes a feed in the background
@@ -263,30 +300,139 @@ func (s *FeedService) processFeedAsync(ctx context.Context, feed *model.Feed) {
 	}
 }
 
-// ProcessFeedNow immediately fetches and processes articles from a feed
+// ProcessFeedNow immediately fetches and processes articles from a feed and records the outcome for its health
 func (s *FeedService) ProcessFeedNow(ctx context.Context, feed *model.Feed) error {
 	if feed == nil {
 		return fmt.Errorf("feed cannot be nil")
 	}
 
+	err := s.processFeed(ctx, feed)
+	s.recordFetch(ctx, feed, err)
+	return err
+}
+
+func (s *FeedService) processFeed(ctx context.Context, feed *model.Feed) error {
+	source, err := s.sources.Get(feed.SourceKind)
+	if err != nil {
+		return fmt.Errorf("failed to get source for feed %s: %w", feed.URL, err)
+	}
+
 	// Read articles from the feed
-	articles, err := s.rssReader.ReadFeed(ctx, feed)
+	articles, meta, err := source.Fetch(ctx, feed)
 	if err != nil {
 		return fmt.Errorf("failed to read feed %s: %w", feed.URL, err)
 	}
 
+	if meta.IconURL != "" && (feed.IconURL == nil || *feed.IconURL != meta.IconURL) {
+		if err := s.repo.UpdateIconURL(ctx, feed.ID, meta.IconURL); err != nil {
+			fmt.Printf("Failed to update icon of feed %s: %v\n", feed.URL, err)
+		}
+	}
+
+	for _, article := range articles {
+		article.Language = detectArticleLanguage(article.Title, article.Description, meta.Language)
+	}
+
 	if len(articles) == 0 {
-		return fmt.Errorf("no articles found in feed %s", feed.URL)
+		return fmt.Errorf("%w %s", ErrFeedEmpty, feed.URL)
+	}
+
+	rules, err := s.ruleService.RulesForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load rules for feed %s: %w", feed.URL, err)
 	}
 
 	// Save articles to database
-	savedCount := 0
-	duplicateCount := 0
+	saved, stats := ingestArticles(ctx, s.articleService, feed, articles, rules, time.Now())
+	for _, article := range saved {
+		if err := s.ruleService.ApplyTags(ctx, article.ID, article.tagIDs); err != nil {
+			fmt.Printf("Failed to apply rule tags to article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+		}
+		if err := s.storyService.AssignCluster(ctx, article.Article); err != nil {
+			fmt.Printf("Failed to cluster article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+		}
+	}
+
+	if err := s.ruleService.RecordMatches(ctx, rules); err != nil {
+		fmt.Printf("Failed to record rule matches of feed %s: %v\n", feed.URL, err)
+	}
+
+	fmt.Printf("Processed feed %s (%s): saved %d new and updated %d changed articles, skipped %d duplicates and %d by rules\n",
+		feed.Name, feed.URL, len(saved), stats.updated, stats.duplicates, stats.ruleSkipped)
+
+	return nil
+}
+
+// articleStore stores the fetched articles of a feed, it is implemented by ArticleService
+type articleStore interface {
+	GetFetchedBefore(ctx context.Context, feedID uuid.UUID, fetched *model.Article) (*model.Article, error)
+	UpdateContent(ctx context.Context, article, fetched *model.Article) (bool, error)
+	IsDuplicate(ctx context.Context, article *model.Article) (bool, error)
+	Save(ctx context.Context, article *model.Article) error
+	SaveSkipped(ctx context.Context, article *model.Article) error
+}
+
+// ingestedArticle is a new article together with the tags its rules add
+type ingestedArticle struct {
+	*model.Article
+	tagIDs []uuid.UUID
+}
+
+type ingestStats struct {
+	updated     int
+	duplicates  int
+	ruleSkipped int
+}
 
+// ingestArticles saves the new articles of the feed after applying the rules and updates changed ones.
+// Articles skipped by a rule are remembered, so they are duplicates on the next fetch and not matched again.
+func ingestArticles(ctx context.Context, store articleStore, feed *model.Feed, articles []*model.Article, rules *RuleSet, now time.Time) ([]*ingestedArticle, ingestStats) {
+	saved := []*ingestedArticle{}
+	var stats ingestStats
 	for _, article := range articles {
-		err := s.articleService.Save(ctx, article)
+		// Changed articles get a new content hash, so they are matched by title, link and publish date to update them instead
+		existing, err := store.GetFetchedBefore(ctx, feed.ID, article)
+		if err != nil {
+			fmt.Printf("Failed to check article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+			continue
+		}
+		if existing != nil {
+			changed, err := store.UpdateContent(ctx, existing, article)
+			if err != nil {
+				fmt.Printf("Failed to update article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+			} else if changed {
+				stats.updated++
+			} else {
+				stats.duplicates++
+			}
+			continue
+		}
+
+		var outcome RuleOutcome
+		if !rules.Empty() {
+			// Rules only apply to new articles, so their statistics are not counted again on every fetch
+			isDuplicate, err := store.IsDuplicate(ctx, article)
+			if err != nil {
+				fmt.Printf("Failed to check article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+				continue
+			}
+			if isDuplicate {
+				stats.duplicates++
+				continue
+			}
+			outcome = rules.Apply(article, now)
+			if outcome.Skip {
+				stats.ruleSkipped++
+				if err := store.SaveSkipped(ctx, article); err != nil {
+					fmt.Printf("Failed to remember skipped article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
+				}
+				continue
+			}
+		}
+
+		err = store.Save(ctx, article)
 		if err == ErrDuplicateArticle {
-			duplicateCount++
+			stats.duplicates++
 			continue
 		}
 		if err != nil {
@@ -294,13 +440,9 @@ func (s *FeedService) ProcessFeedNow(ctx context.Context, feed *model.Feed) erro
 			fmt.Printf("Failed to save article '%s' from feed %s: %v\n", article.Title, feed.URL, err)
 			continue
 		}
-		savedCount++
+		saved = append(saved, &ingestedArticle{Article: article, tagIDs: outcome.TagIDs})
 	}
-
-	fmt.Printf("Processed feed %s (%s): saved %d new articles, skipped %d duplicates\n",
-		feed.Name, feed.URL, savedCount, duplicateCount)
-
-	return nil
+	return saved, stats
 }
 
 // ProcessFeedByID processes a feed by its ID
@@ -327,3 +469,17 @@ func (s *FeedService) UpdateLastReadAt(ctx context.Context, feedID uuid.UUID) er
 
 	return nil
 }
+
+// GetNewArticles returns a page of the articles of the feed published after it was last read
+func (s *FeedService) GetNewArticles(ctx context.Context, feedID uuid.UUID, page model.PageRequest, filter model.ArticleFilter) (*model.ArticlePage, error) {
+	feed, err := s.GetByID(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filter.PublishedAfter = &feed.LastReadAt
+	return s.articleService.GetPaginatedByFeedID(ctx, feed.ID, page, filter)
+}
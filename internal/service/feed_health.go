@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+const (
+	// feedHealthWindow is the period the average number of articles per day is computed over
+	feedHealthWindow = 30 * 24 * time.Hour
+	// feedStaleAfter is the time without new articles after which a working feed is stale
+	feedStaleAfter = 14 * 24 * time.Hour
+	// feedFailingAfter is the number of failed fetches in a row after which a feed is failing
+	feedFailingAfter = 3
+	// feedDeadAfter is the time without a successful fetch after which a failing feed is dead
+	feedDeadAfter = 30 * 24 * time.Hour
+)
+
+// GetHealth returns the fetch results, publishing activity and computed status of all feeds
+func (s *FeedService) GetHealth(ctx context.Context, now time.Time) ([]*model.FeedHealth, error) {
+	health, err := s.repo.GetHealth(ctx, now.Add(-feedHealthWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed health: %w", err)
+	}
+	for _, h := range health {
+		computeFeedHealth(h, now)
+	}
+	return health, nil
+}
+
+// Revalidate validates all feeds like on creation and records the results, which mark broken feeds as failing
+// even if their fetches are not attempted or keep timing out
+func (s *FeedService) Revalidate(ctx context.Context) (*model.FeedValidationResult, error) {
+	feeds, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all feeds: %w", err)
+	}
+
+	result := &model.FeedValidationResult{}
+	for _, feed := range feeds {
+		var validationErr string
+		if _, err := s.validateSource(ctx, feed.SourceKind, feed.URL, feed.SourceConfig); err != nil {
+			validationErr = err.Error()
+			result.Failed++
+		}
+		result.Validated++
+		if err := s.repo.RecordValidation(ctx, feed.ID, time.Now(), validationErr); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// recordFetch stores the outcome of a fetch for the health of the feed. It runs even if the fetch was
+// canceled, so a timeout counts as a failure.
+func (s *FeedService) recordFetch(ctx context.Context, feed *model.Feed, fetchErr error) {
+	ctx = context.WithoutCancel(ctx)
+	var err error
+	// An empty feed was fetched successfully, it becomes stale if it stays quiet
+	if fetchErr != nil && !errors.Is(fetchErr, ErrFeedEmpty) {
+		err = s.repo.RecordFetchFailure(ctx, feed.ID, time.Now(), fetchErr.Error())
+	} else {
+		err = s.repo.RecordFetchSuccess(ctx, feed.ID, time.Now())
+	}
+	if err != nil {
+		fmt.Printf("Failed to record fetch of feed %s: %v\n", feed.URL, err)
+	}
+}
+
+// computeFeedHealth sets the average articles per day, the days since the last article and the status
+func computeFeedHealth(h *model.FeedHealth, now time.Time) {
+	window := feedHealthWindow
+	if age := now.Sub(h.CreatedAt); age < window {
+		window = max(age, 24*time.Hour)
+	}
+	h.AvgItemsPerDay = math.Round(float64(h.RecentCount)/window.Hours()*24*100) / 100
+
+	h.DaysSinceLastItem = nil
+	if h.LastPublishedAt != nil {
+		days := int(max(now.Sub(*h.LastPublishedAt), 0) / (24 * time.Hour))
+		h.DaysSinceLastItem = &days
+	}
+	h.Status = feedHealthStatus(h, now)
+}
+
+// feedHealthStatus computes the status of a feed. A feed is failing after feedFailingAfter failed fetches in a row
+// or a failed validation without a successful fetch since, and dead if it has not been fetched successfully for feedDeadAfter since it was added.
+// A working feed is stale if its newest article is older than feedStaleAfter.
+func feedHealthStatus(h *model.FeedHealth, now time.Time) model.FeedHealthStatus {
+	if h.ConsecutiveFailures >= feedFailingAfter || validationFailing(h) {
+		lastSuccess := h.CreatedAt
+		if h.LastSuccessAt != nil && h.LastSuccessAt.After(lastSuccess) {
+			lastSuccess = *h.LastSuccessAt
+		}
+		if now.Sub(lastSuccess) >= feedDeadAfter {
+			return model.FeedHealthStatusDead
+		}
+		return model.FeedHealthStatusFailing
+	}
+
+	lastItem := h.CreatedAt
+	if h.LastPublishedAt != nil {
+		lastItem = *h.LastPublishedAt
+	}
+	if now.Sub(lastItem) >= feedStaleAfter {
+		return model.FeedHealthStatusStale
+	}
+	return model.FeedHealthStatusHealthy
+}
+
+// validationFailing reports whether the last validation failed after the last successful fetch
+func validationFailing(h *model.FeedHealth) bool {
+	if h.ValidationError == "" || h.LastValidatedAt == nil {
+		return false
+	}
+	return h.LastSuccessAt == nil || h.LastValidatedAt.After(*h.LastSuccessAt)
+}
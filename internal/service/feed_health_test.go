@@ -0,0 +1,107 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestComputeFeedHealth(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		at := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		health     model.FeedHealth
+		wantStatus model.FeedHealthStatus
+		wantAvg    float64
+	}{
+		{
+			name:       "healthy",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(0), LastPublishedAt: daysAgo(2), RecentCount: 45},
+			wantStatus: model.FeedHealthStatusHealthy,
+			wantAvg:    1.5,
+		},
+		{
+			name:       "new feed averages since it was added",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(3), LastPublishedAt: daysAgo(0), RecentCount: 9},
+			wantStatus: model.FeedHealthStatusHealthy,
+			wantAvg:    3,
+		},
+		{
+			name:       "stale without new articles",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(0), LastPublishedAt: daysAgo(20)},
+			wantStatus: model.FeedHealthStatusStale,
+		},
+		{
+			name:       "stale without any article",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(15), LastSuccessAt: daysAgo(0)},
+			wantStatus: model.FeedHealthStatusStale,
+		},
+		{
+			name:       "a few failures are tolerated",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(1), LastPublishedAt: daysAgo(1), ConsecutiveFailures: 2},
+			wantStatus: model.FeedHealthStatusHealthy,
+		},
+		{
+			name:       "failing",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(5), LastPublishedAt: daysAgo(5), ConsecutiveFailures: 3},
+			wantStatus: model.FeedHealthStatusFailing,
+		},
+		{
+			name:       "failed validation",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(1), LastPublishedAt: daysAgo(1), LastValidatedAt: daysAgo(0), ValidationError: "not a feed"},
+			wantStatus: model.FeedHealthStatusFailing,
+		},
+		{
+			name:       "fetched successfully after failed validation",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(0), LastPublishedAt: daysAgo(1), LastValidatedAt: daysAgo(3), ValidationError: "timeout"},
+			wantStatus: model.FeedHealthStatusHealthy,
+		},
+		{
+			name:       "dead",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(40), LastPublishedAt: daysAgo(60), ConsecutiveFailures: 200},
+			wantStatus: model.FeedHealthStatusDead,
+		},
+		{
+			name:       "never fetched but recently added",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(2), ConsecutiveFailures: 10},
+			wantStatus: model.FeedHealthStatusFailing,
+		},
+		{
+			name:       "future publish dates",
+			health:     model.FeedHealth{CreatedAt: *daysAgo(100), LastSuccessAt: daysAgo(0), LastPublishedAt: daysAgo(-3)},
+			wantStatus: model.FeedHealthStatusHealthy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := tt.health
+			computeFeedHealth(&h, now)
+			if h.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, h.Status)
+			}
+			if h.AvgItemsPerDay != tt.wantAvg {
+				t.Errorf("Expected %g items per day, got %g", tt.wantAvg, h.AvgItemsPerDay)
+			}
+			if (h.DaysSinceLastItem == nil) != (h.LastPublishedAt == nil) {
+				t.Errorf("Expected days since last item to be set with a last publish date, got %v", h.DaysSinceLastItem)
+			}
+		})
+	}
+}
+
+func TestComputeFeedHealth_DaysSinceLastItem(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	published := now.Add(-50 * time.Hour)
+	h := model.FeedHealth{CreatedAt: now.Add(-100 * 24 * time.Hour), LastPublishedAt: &published}
+
+	computeFeedHealth(&h, now)
+	if h.DaysSinceLastItem == nil || *h.DaysSinceLastItem != 2 {
+		t.Errorf("Expected 2 days since the last item, got %v", h.DaysSinceLastItem)
+	}
+}
@@ -75,7 +75,7 @@ func ExampleFeedService_Create_withValidation() {
 	mockRepo := &repository.FeedRepository{}
 	mockRssReader := &RssArticleReader{}
 	mockArticleService := &ArticleService{}
-	feedService := NewFeedService(mockRepo, mockRssReader, mockArticleService)
+	feedService := NewFeedService(mockRepo, NewSourceRegistry(mockRssReader), mockArticleService, &StoryService{}, &RuleService{})
 
 	req := &model.CreateFeedRequest{
 		Name: "Example RSS Feed",
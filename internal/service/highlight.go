@@ -0,0 +1,227 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrHighlightNotFound = errors.New("highlight not found")
+	ErrInvalidHighlight  = errors.New("invalid highlight")
+)
+
+var highlightColors = map[model.HighlightColor]bool{
+	model.HighlightColorYellow: true,
+	model.HighlightColorGreen:  true,
+	model.HighlightColorBlue:   true,
+	model.HighlightColorPink:   true,
+	model.HighlightColorPurple: true,
+}
+
+type HighlightService struct {
+	repo        *repository.HighlightRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewHighlightService(repo *repository.HighlightRepository, articleRepo *repository.ArticleRepository) *HighlightService {
+	return &HighlightService{repo: repo, articleRepo: articleRepo}
+}
+
+// GetByArticleID returns the highlights of the article in the order of their position in the text
+func (s *HighlightService) GetByArticleID(ctx context.Context, articleID uuid.UUID) ([]*model.Highlight, error) {
+	if err := s.checkArticle(ctx, articleID); err != nil {
+		return nil, err
+	}
+
+	highlights, err := s.repo.GetByArticleID(ctx, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights of article %s: %w", articleID, err)
+	}
+	return highlights, nil
+}
+
+func (s *HighlightService) Create(ctx context.Context, articleID uuid.UUID, req *model.CreateHighlightRequest) (*model.Highlight, error) {
+	if strings.TrimSpace(req.Quote) == "" {
+		return nil, fmt.Errorf("%w: quote cannot be empty", ErrInvalidHighlight)
+	}
+	if req.Anchor.Start < 0 || req.Anchor.End <= req.Anchor.Start {
+		return nil, fmt.Errorf("%w: anchor end must be after its start, which cannot be negative", ErrInvalidHighlight)
+	}
+	color, err := validateHighlightColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkArticle(ctx, articleID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	highlight := &model.Highlight{
+		ID:        uuid.New(),
+		ArticleID: articleID,
+		Quote:     req.Quote,
+		Anchor:    req.Anchor,
+		Color:     color,
+		Note:      strings.TrimSpace(req.Note),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	createdHighlight, err := s.repo.Create(ctx, highlight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create highlight: %w", err)
+	}
+	return createdHighlight, nil
+}
+
+func (s *HighlightService) Update(ctx context.Context, articleID, id uuid.UUID, req *model.UpdateHighlightRequest) (*model.Highlight, error) {
+	color, err := validateHighlightColor(req.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Update(ctx, articleID, id, color, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update highlight with ID %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrHighlightNotFound
+	}
+
+	highlight, err := s.repo.GetByID(ctx, articleID, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlight with ID %s: %w", id, err)
+	}
+	return highlight, nil
+}
+
+func (s *HighlightService) Delete(ctx context.Context, articleID, id uuid.UUID) error {
+	found, err := s.repo.Delete(ctx, articleID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete highlight with ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrHighlightNotFound
+	}
+	return nil
+}
+
+// UpdateArticleNote sets the free-form note of the article, an empty note removes it
+func (s *HighlightService) UpdateArticleNote(ctx context.Context, articleID uuid.UUID, req *model.UpdateArticleNoteRequest) (*model.Article, error) {
+	found, err := s.articleRepo.UpdateNoteByID(ctx, articleID, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update note of article %s: %w", articleID, err)
+	}
+	if !found {
+		return nil, ErrArticleNotFound
+	}
+
+	article, err := s.articleRepo.GetByID(ctx, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get article with ID %s: %w", articleID, err)
+	}
+	return article, nil
+}
+
+// ExportMarkdown returns the notes and highlights of all annotated articles as Markdown, newest article first
+func (s *HighlightService) ExportMarkdown(ctx context.Context) ([]byte, error) {
+	ids, err := s.repo.GetAnnotatedArticleIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotated articles: %w", err)
+	}
+	articles, err := s.articleRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotated articles: %w", err)
+	}
+	highlights, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get highlights: %w", err)
+	}
+
+	annotated := make([]*model.AnnotatedArticle, len(articles))
+	for i, article := range articles {
+		annotated[i] = &model.AnnotatedArticle{Article: article, Highlights: highlights[article.ID]}
+	}
+	sort.SliceStable(annotated, func(i, j int) bool {
+		return annotated[i].Article.PublishedAt.After(annotated[j].Article.PublishedAt)
+	})
+	return renderHighlightsMarkdown(annotated), nil
+}
+
+func (s *HighlightService) checkArticle(ctx context.Context, articleID uuid.UUID) error {
+	_, err := s.articleRepo.GetByID(ctx, articleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get article with ID %s: %w", articleID, err)
+	}
+	return nil
+}
+
+// validateHighlightColor returns the color or yellow if it is empty
+func validateHighlightColor(color model.HighlightColor) (model.HighlightColor, error) {
+	if color == "" {
+		return model.HighlightColorYellow, nil
+	}
+	if !highlightColors[color] {
+		return "", fmt.Errorf("%w: unknown color %q", ErrInvalidHighlight, color)
+	}
+	return color, nil
+}
+
+// renderHighlightsMarkdown renders one section per article with its note and its highlights as block quotes,
+// which Obsidian and Logseq import as is. Color and note of a highlight are nested under its quote.
+func renderHighlightsMarkdown(articles []*model.AnnotatedArticle) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Highlights\n")
+	for _, annotated := range articles {
+		article := annotated.Article
+		fmt.Fprintf(&b, "\n## %s\n\n", strings.Join(strings.Fields(article.Title), " "))
+		fmt.Fprintf(&b, "- URL: %s\n", article.SourceUrl)
+		fmt.Fprintf(&b, "- Published: %s\n", article.PublishedAt.Format(time.DateOnly))
+
+		if article.Note != "" {
+			fmt.Fprintf(&b, "\n### Note\n\n%s\n", article.Note)
+		}
+		if len(annotated.Highlights) > 0 {
+			b.WriteString("\n### Highlights\n")
+		}
+		for _, highlight := range annotated.Highlights {
+			b.WriteString("\n")
+			writeMarkdownQuote(&b, "> ", highlight.Quote)
+			var details []string
+			if highlight.Color != "" {
+				details = append(details, "**Color:** "+string(highlight.Color))
+			}
+			if highlight.Note != "" {
+				details = append(details, "**Note:** "+highlight.Note)
+			}
+			if len(details) > 0 {
+				b.WriteString(">\n")
+				writeMarkdownQuote(&b, "> > ", strings.Join(details, "\n\n"))
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+// writeMarkdownQuote writes every line of the text with the block quote prefix
+func writeMarkdownQuote(b *bytes.Buffer, prefix, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			b.WriteString(strings.TrimSpace(prefix) + "\n")
+		} else {
+			b.WriteString(prefix + line + "\n")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestValidateHighlightColor(t *testing.T) {
+	if color, err := validateHighlightColor(""); err != nil || color != model.HighlightColorYellow {
+		t.Errorf("Expected yellow as default, got %q and %v", color, err)
+	}
+	if _, err := validateHighlightColor("orange"); !errors.Is(err, ErrInvalidHighlight) {
+		t.Errorf("Expected ErrInvalidHighlight, got %v", err)
+	}
+}
+
+func TestRenderHighlightsMarkdown(t *testing.T) {
+	articles := []*model.AnnotatedArticle{
+		{
+			Article: &model.Article{
+				Title:       "Example\n Article",
+				SourceUrl:   "https://example.com/article",
+				PublishedAt: time.Date(2023, 10, 11, 10, 0, 0, 0, time.UTC),
+				Note:        "Relevant for chapter 2",
+			},
+			Highlights: []*model.Highlight{
+				{Quote: "First paragraph\n\nSecond paragraph", Color: model.HighlightColorGreen, Note: "Check the numbers\nand the sources"},
+				{Quote: "Another quote"},
+			},
+		},
+	}
+
+	want := `# Highlights
+
+## Example Article
+
+- URL: https://example.com/article
+- Published: 2023-10-11
+
+### Note
+
+Relevant for chapter 2
+
+### Highlights
+
+> First paragraph
+>
+> Second paragraph
+>
+> > **Color:** green
+> >
+> > **Note:** Check the numbers
+> > and the sources
+
+> Another quote
+`
+	if got := string(renderHighlightsMarkdown(articles)); got != want {
+		t.Errorf("renderHighlightsMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
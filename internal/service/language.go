@@ -0,0 +1,265 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// minLanguageDetectionRunes is the minimum amount of letters required to trust the detector
+	minLanguageDetectionRunes = 20
+	// minLanguageConfidence is the minimum probability of the best language
+	minLanguageConfidence = 0.9
+	maxLanguageNgramSize  = 3
+	languageSmoothing     = 0.1
+)
+
+// languageSamples contains short reference texts per language. The n-gram profiles of the
+// detector are built from them at startup, so adding a language only requires a new sample.
+var languageSamples = map[string]string{
+	"de": `Die Bundesregierung hat am Mittwoch einen neuen Gesetzentwurf vorgestellt, der die Rechte
+		von Mietern stärken soll. Nach Angaben des Ministeriums sollen die Mieten in Städten mit
+		angespanntem Wohnungsmarkt künftig nur noch langsamer steigen dürfen. Kritiker aus der
+		Opposition sprechen von einem Schritt in die richtige Richtung, der aber nicht weit genug gehe.
+		Auch die Wirtschaft meldete sich zu Wort und warnte vor zu viel Bürokratie für kleine Vermieter.
+		Unterdessen ist das Wetter in weiten Teilen Deutschlands wechselhaft geblieben. In den Alpen
+		fiel der erste Schnee, während es im Norden bei kräftigem Wind immer wieder zu Schauern kam.
+		Der Deutsche Wetterdienst rechnet für das Wochenende mit etwas mehr Sonne und milderen
+		Temperaturen. Im Fußball hat die Mannschaft aus München ihr Spiel gegen den Tabellenzweiten
+		deutlich gewonnen und steht damit weiterhin an der Spitze der Liga. Die Forscher der
+		Universität haben außerdem herausgefunden, dass sich die Zahl der Vögel in den Städten in
+		den letzten zehn Jahren kaum verändert hat. Es ist nicht klar, ob sich das noch ändern wird,
+		weil die Daten über einen längeren Zeitraum ausgewertet werden müssen.
+		Ein Technologiekonzern hat ein neues Smartphone mit schnellerem Chip und besserer Kamera
+		vorgestellt. Wissenschaftler entdeckten zudem Wasser auf einem weit entfernten Planeten und
+		wollen nun mit einem Teleskop nach weiteren Spuren suchen. Die Kanzlerin trifft sich morgen
+		mit dem französischen Präsidenten, um über die Zukunft Europas und die Sicherheit zu sprechen.
+		Nach wochenlangen Verhandlungen haben sich Gewerkschaften und Arbeitgeber auf einen Vertrag
+		geeinigt, der höhere Löhne für die Beschäftigten vorsieht.`,
+	"en": `The government announced on Wednesday a new bill that is meant to strengthen the rights
+		of tenants. According to the ministry, rents in cities with a tight housing market should only
+		be allowed to rise more slowly in the future. Critics from the opposition called it a step in
+		the right direction, but said that it does not go far enough. The business community also
+		weighed in and warned about too much bureaucracy for small landlords. Meanwhile the weather
+		has remained changeable in large parts of the country. The first snow fell in the mountains,
+		while strong winds brought repeated showers to the north. The weather service expects a little
+		more sunshine and milder temperatures for the weekend. In football, the team from the capital
+		clearly won their match against the second placed side and are still at the top of the league.
+		Researchers at the university have also found that the number of birds in cities has hardly
+		changed over the last ten years. It is not clear whether this will change, because the data
+		has to be analysed over a longer period of time before anyone can say what happened there.
+		A technology company unveiled a new smartphone with a faster chip and a better camera.
+		Scientists also discovered water on a distant planet and now want to look for further signs
+		with a telescope. The prime minister will meet the French president tomorrow to talk about the
+		future of Europe and about security. After weeks of negotiations, the unions and employers
+		have agreed on a contract which includes higher wages for the workers who walked out.`,
+	"fr": `Le gouvernement a présenté mercredi un nouveau projet de loi qui doit renforcer les droits
+		des locataires. Selon le ministère, les loyers dans les villes où le marché du logement est
+		tendu ne pourront plus augmenter que plus lentement à l'avenir. Les critiques de l'opposition
+		parlent d'un pas dans la bonne direction, mais estiment qu'il ne va pas assez loin. Les
+		entreprises se sont également exprimées et ont mis en garde contre une bureaucratie trop
+		lourde pour les petits propriétaires. Pendant ce temps, le temps est resté variable dans une
+		grande partie du pays. La première neige est tombée dans les montagnes, tandis que des vents
+		forts ont apporté des averses répétées dans le nord. Le service météorologique prévoit un peu
+		plus de soleil et des températures plus douces pour le week-end. Au football, l'équipe de la
+		capitale a nettement remporté son match contre le deuxième du classement et reste en tête du
+		championnat. Les chercheurs de l'université ont aussi constaté que le nombre d'oiseaux dans
+		les villes n'a presque pas changé au cours des dix dernières années.
+		Une entreprise technologique a dévoilé un nouveau smartphone avec une puce plus rapide et un
+		meilleur appareil photo. Des scientifiques ont aussi découvert de l'eau sur une planète
+		lointaine et veulent maintenant chercher d'autres traces avec un télescope. Le premier
+		ministre rencontrera demain le président allemand pour parler de l'avenir de l'Europe et de
+		la sécurité. Après des semaines de négociations, les syndicats et les employeurs se sont mis
+		d'accord sur un contrat qui prévoit des salaires plus élevés pour les salariés.`,
+	"es": `El gobierno presentó el miércoles un nuevo proyecto de ley que pretende reforzar los
+		derechos de los inquilinos. Según el ministerio, los alquileres en las ciudades con un mercado
+		de vivienda tenso solo podrán subir más despacio en el futuro. Los críticos de la oposición
+		hablan de un paso en la dirección correcta, pero creen que no llega lo suficientemente lejos.
+		Las empresas también se pronunciaron y advirtieron de una burocracia excesiva para los
+		pequeños propietarios. Mientras tanto, el tiempo ha seguido siendo variable en gran parte del
+		país. La primera nieve cayó en las montañas, mientras que los fuertes vientos trajeron
+		chubascos repetidos al norte. El servicio meteorológico espera un poco más de sol y
+		temperaturas más suaves para el fin de semana. En el fútbol, el equipo de la capital ganó con
+		claridad su partido contra el segundo clasificado y sigue en lo más alto de la liga. Los
+		investigadores de la universidad también han descubierto que el número de aves en las
+		ciudades apenas ha cambiado en los últimos diez años.
+		Una empresa tecnológica presentó un nuevo teléfono con un chip más rápido y una cámara mejor.
+		Los científicos también descubrieron agua en un planeta lejano y ahora quieren buscar más
+		señales con un telescopio. El presidente del gobierno se reunirá mañana con el presidente
+		francés para hablar sobre el futuro de Europa y la seguridad. Tras semanas de negociaciones,
+		los sindicatos y los empresarios han llegado a un acuerdo que prevé salarios más altos para
+		los trabajadores.`,
+	"it": `Il governo ha presentato mercoledì un nuovo disegno di legge che dovrebbe rafforzare i
+		diritti degli inquilini. Secondo il ministero, gli affitti nelle città con un mercato
+		immobiliare difficile potranno aumentare solo più lentamente in futuro. I critici
+		dell'opposizione parlano di un passo nella giusta direzione, ma ritengono che non vada
+		abbastanza lontano. Anche le imprese hanno preso la parola e hanno messo in guardia contro
+		troppa burocrazia per i piccoli proprietari. Nel frattempo il tempo è rimasto variabile in
+		gran parte del paese. La prima neve è caduta sulle montagne, mentre venti forti hanno portato
+		rovesci ripetuti al nord. Il servizio meteorologico prevede un po' più di sole e temperature
+		più miti per il fine settimana. Nel calcio, la squadra della capitale ha vinto nettamente la
+		partita contro la seconda in classifica e resta in testa al campionato. I ricercatori
+		dell'università hanno inoltre scoperto che il numero degli uccelli nelle città è rimasto
+		quasi invariato negli ultimi dieci anni.
+		Un'azienda tecnologica ha presentato un nuovo smartphone con un chip più veloce e una
+		fotocamera migliore. Gli scienziati hanno anche scoperto acqua su un pianeta lontano e ora
+		vogliono cercare altre tracce con un telescopio. Il presidente del consiglio incontrerà domani
+		il presidente francese per parlare del futuro dell'Europa e della sicurezza. Dopo settimane di
+		trattative, i sindacati e i datori di lavoro hanno raggiunto un accordo che prevede stipendi
+		più alti per i lavoratori.`,
+	"nl": `De regering heeft woensdag een nieuw wetsvoorstel gepresenteerd dat de rechten van
+		huurders moet versterken. Volgens het ministerie mogen de huren in steden met een krappe
+		woningmarkt in de toekomst alleen nog langzamer stijgen. Critici uit de oppositie spreken van
+		een stap in de goede richting, maar vinden dat het niet ver genoeg gaat. Ook het bedrijfsleven
+		liet van zich horen en waarschuwde voor te veel bureaucratie voor kleine verhuurders.
+		Ondertussen is het weer in grote delen van het land wisselvallig gebleven. In de bergen viel
+		de eerste sneeuw, terwijl harde wind in het noorden steeds weer voor buien zorgde. De
+		weerdienst verwacht voor het weekend iets meer zon en mildere temperaturen. In het voetbal
+		heeft het team uit de hoofdstad zijn wedstrijd tegen de nummer twee duidelijk gewonnen en
+		staat daarmee nog steeds bovenaan in de competitie. Onderzoekers van de universiteit hebben
+		bovendien ontdekt dat het aantal vogels in de steden de afgelopen tien jaar nauwelijks is
+		veranderd.
+		Een technologiebedrijf heeft een nieuwe smartphone met een snellere chip en een betere camera
+		gepresenteerd. Wetenschappers ontdekten ook water op een verre planeet en willen nu met een
+		telescoop naar meer sporen zoeken. De premier ontmoet morgen de Franse president om te praten
+		over de toekomst van Europa en de veiligheid. Na weken van onderhandelingen zijn de vakbonden
+		en de werkgevers het eens geworden over een contract met hogere lonen voor de werknemers.`,
+}
+
+// LanguageDetector detects the language of a text by comparing its character n-grams
+// with n-gram profiles built from the reference samples.
+type LanguageDetector struct {
+	profiles map[string]*languageProfile
+}
+
+// languageProfile holds the n-gram counts of one language, indexed by n-gram length - 1.
+type languageProfile struct {
+	counts [maxLanguageNgramSize]map[string]int
+	totals [maxLanguageNgramSize]int
+}
+
+var (
+	defaultLanguageDetector     *LanguageDetector
+	defaultLanguageDetectorOnce sync.Once
+)
+
+// DefaultLanguageDetector returns a shared detector built from languageSamples.
+func DefaultLanguageDetector() *LanguageDetector {
+	defaultLanguageDetectorOnce.Do(func() {
+		defaultLanguageDetector = NewLanguageDetector(languageSamples)
+	})
+	return defaultLanguageDetector
+}
+
+// NewLanguageDetector builds a detector from reference texts keyed by ISO 639-1 language code.
+func NewLanguageDetector(samples map[string]string) *LanguageDetector {
+	detector := &LanguageDetector{profiles: make(map[string]*languageProfile, len(samples))}
+	for lang, sample := range samples {
+		profile := &languageProfile{}
+		for n := 1; n <= maxLanguageNgramSize; n++ {
+			profile.counts[n-1] = make(map[string]int)
+			for _, gram := range textNgrams(sample, n) {
+				profile.counts[n-1][gram]++
+				profile.totals[n-1]++
+			}
+		}
+		detector.profiles[lang] = profile
+	}
+	return detector
+}
+
+// Detect returns the most likely language of text and a confidence between 0 and 1.
+// It returns an empty language if the text is too short or no language is clearly ahead.
+func (d *LanguageDetector) Detect(text string) (string, float64) {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < minLanguageDetectionRunes || len(d.profiles) == 0 {
+		return "", 0
+	}
+
+	var grams [maxLanguageNgramSize][]string
+	for n := 1; n <= maxLanguageNgramSize; n++ {
+		grams[n-1] = textNgrams(text, n)
+	}
+
+	// log likelihood of all n-grams with additive smoothing
+	scores := make(map[string]float64, len(d.profiles))
+	for lang, profile := range d.profiles {
+		score := 0.0
+		for i, ngrams := range grams {
+			denominator := float64(profile.totals[i]) + languageSmoothing*float64(len(profile.counts[i])+1)
+			for _, gram := range ngrams {
+				score += math.Log((float64(profile.counts[i][gram]) + languageSmoothing) / denominator)
+			}
+		}
+		scores[lang] = score
+	}
+
+	bestLang, best := "", math.Inf(-1)
+	for lang, score := range scores {
+		if score > best || (score == best && lang < bestLang) {
+			bestLang, best = lang, score
+		}
+	}
+
+	// posterior probability of the best language assuming equal priors
+	sum := 0.0
+	for _, score := range scores {
+		sum += math.Exp(score - best)
+	}
+	confidence := 1 / sum
+	if confidence < minLanguageConfidence {
+		return "", confidence
+	}
+	return bestLang, confidence
+}
+
+// textNgrams splits text into lower-cased words and returns their space padded character n-grams.
+func textNgrams(text string, n int) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	var grams []string
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+n <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+n]))
+		}
+	}
+	return grams
+}
+
+// NormalizeLanguage reduces a language tag like "de-DE" or "en_us" to its lower-cased
+// ISO 639-1 code. It returns an empty string if the tag is not a valid two letter code.
+func NormalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) != 2 {
+		return ""
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return ""
+		}
+	}
+	return tag
+}
+
+// detectArticleLanguage detects the language from the article title and description
+// and falls back to the language declared by the feed.
+func detectArticleLanguage(title, description, feedLanguage string) string {
+	text := title + ". " + plainText(description)
+	if lang, _ := DefaultLanguageDetector().Detect(text); lang != "" {
+		return lang
+	}
+	return NormalizeLanguage(feedLanguage)
+}
@@ -0,0 +1,61 @@
+package service
+
+import "testing"
+
+func TestLanguageDetector_Detect(t *testing.T) {
+	detector := DefaultLanguageDetector()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"german headline", "Bundestag beschließt neues Gesetz zur Förderung von Wärmepumpen in Privathaushalten", "de"},
+		{"english headline", "Parliament passes new law to support heat pumps in private households", "en"},
+		{"german teaser", "Die Gewerkschaft ruft zu einem bundesweiten Streik auf, nachdem die Gespräche gescheitert sind.", "de"},
+		{"english teaser", "The union calls for a nationwide strike after talks with the employers broke down over pay.", "en"},
+		{"french", "Le président a annoncé de nouvelles mesures pour soutenir les familles cette année.", "fr"},
+		{"too short", "Breaking", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := detector.Detect(tt.text)
+			if got != tt.want {
+				t.Errorf("Expected language %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectArticleLanguage_FallsBackToFeedLanguage(t *testing.T) {
+	got := detectArticleLanguage("Live", "<p>+++</p>", "de-DE")
+	if got != "de" {
+		t.Errorf("Expected fallback language %q, got %q", "de", got)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"de":    "de",
+		"en-US": "en",
+		"de_at": "de",
+		" EN ":  "en",
+		"deu":   "",
+		"1a":    "",
+		"":      "",
+	}
+	for input, want := range tests {
+		if got := NormalizeLanguage(input); got != want {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	got := plainText(`<p>Hello <b>world</b></p><script>alert("x")</script><style>p{}</style>&amp; more`)
+	want := "Hello world & more"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
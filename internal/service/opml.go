@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+var ErrInvalidOPML = errors.New("invalid OPML")
+
+const (
+	// maxOPMLFeeds is the maximum number of feeds in an imported OPML file
+	maxOPMLFeeds = 1000
+	// opmlValidationWorkers is the number of feed URLs validated at the same time during an import
+	opmlValidationWorkers = 8
+	// opmlValidationTimeout bounds the validation of all feeds of an import. It is shorter than the timeout of
+	// the import route, so the validated feeds can still be created and reported.
+	opmlValidationTimeout = 8 * time.Minute
+)
+
+// opmlDocument is an OPML 1.0 or 2.0 document, see http://opml.org/spec2.opml
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []*opmlOutline `xml:"outline"`
+}
+
+// opmlOutline is a feed if it has an xmlUrl, otherwise a folder of its nested outlines
+type opmlOutline struct {
+	Text     string         `xml:"text,attr"`
+	Title    string         `xml:"title,attr,omitempty"`
+	Type     string         `xml:"type,attr,omitempty"`
+	XMLURL   string         `xml:"xmlUrl,attr,omitempty"`
+	Outlines []*opmlOutline `xml:"outline"`
+}
+
+// OPMLService imports and exports the feed subscriptions as OPML, folders are mapped to categories
+type OPMLService struct {
+	feedService     *FeedService
+	categoryService *CategoryService
+}
+
+func NewOPMLService(feedService *FeedService, categoryService *CategoryService) *OPMLService {
+	return &OPMLService{feedService: feedService, categoryService: categoryService}
+}
+
+// Import creates the feeds of the OPML file. Feeds with an invalid URL and feeds whose URL already exists
+// are skipped and reported. Categories are flat, so each feed is put into the category named after its
+// closest folder, which is created if there is none. Feeds not validated within opmlValidationTimeout are
+// reported as cancelled. Once validated, the feeds are created even if the request is cancelled, and errors
+// are reported per feed, so the report matches the feeds that were created.
+func (s *OPMLService) Import(ctx context.Context, r io.Reader) (*model.OPMLImportResult, error) {
+	entries, err := parseOPML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntries(ctx, entries); err != nil {
+		return nil, err
+	}
+	validateCtx, cancel := context.WithTimeout(ctx, opmlValidationTimeout)
+	s.validateEntries(validateCtx, entries)
+	cancel()
+
+	ctx = context.WithoutCancel(ctx)
+	categories, err := s.getCategoriesByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoryEntries := make(map[uuid.UUID][]*model.OPMLImportEntry)
+	var created []*model.Feed
+	result := &model.OPMLImportResult{Entries: entries}
+	for _, entry := range entries {
+		switch entry.Status {
+		case model.OPMLImportStatusDuplicate:
+			result.Duplicates++
+			continue
+		case model.OPMLImportStatusInvalid:
+			result.Invalid++
+			continue
+		case model.OPMLImportStatusCancelled:
+			result.Cancelled++
+			continue
+		}
+
+		feed, err := s.createFeed(ctx, entry)
+		if err != nil {
+			entry.Status = model.OPMLImportStatusFailed
+			entry.Error = err.Error()
+			result.Failed++
+			continue
+		}
+		created = append(created, feed)
+		entry.Status = model.OPMLImportStatusImported
+		entry.FeedID = &feed.ID
+		result.Imported++
+
+		if entry.Category == "" {
+			continue
+		}
+		category, err := s.getOrCreateCategory(ctx, categories, entry.Category)
+		if err != nil {
+			entry.Category = ""
+			entry.Error = fmt.Sprintf("failed to add feed to category: %v", err)
+			continue
+		}
+		if category == nil {
+			// the folder name is not a valid category name, the feed stays uncategorized
+			entry.Category = ""
+			continue
+		}
+		entry.Category = category.Name
+		categoryEntries[category.ID] = append(categoryEntries[category.ID], entry)
+	}
+
+	for categoryID, entries := range categoryEntries {
+		if err := s.appendCategoryFeeds(ctx, categoryID, entries); err != nil {
+			for _, entry := range entries {
+				entry.Category = ""
+				entry.Error = fmt.Sprintf("failed to add feed to category: %v", err)
+			}
+		}
+	}
+
+	// fetch the new feeds one after another instead of all at once
+	go func() {
+		for _, feed := range created {
+			s.feedService.processFeedAsync(context.Background(), feed)
+		}
+	}()
+	return result, nil
+}
+
+// Export writes all feeds as an OPML 2.0 document, with an outline per category holding its feeds in their
+// order and the uncategorized feeds last. Feeds of other source kinds than RSS are left out.
+func (s *OPMLService) Export(ctx context.Context, w io.Writer, now time.Time) error {
+	categories, err := s.categoryService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	feeds, err := s.feedService.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Head:    opmlHead{Title: "Fyrss subscriptions", DateCreated: now.UTC().Format(time.RFC1123Z)},
+		Body:    opmlBody{Outlines: []*opmlOutline{}},
+	}
+	for _, category := range categories {
+		folder := &opmlOutline{Text: category.Name, Title: category.Name}
+		for _, feed := range category.Feeds {
+			if feed.SourceKind == model.SourceKindRSS {
+				folder.Outlines = append(folder.Outlines, newOPMLFeedOutline(feed))
+			}
+		}
+		if len(folder.Outlines) > 0 {
+			doc.Body.Outlines = append(doc.Body.Outlines, folder)
+		}
+	}
+	for _, feed := range feeds {
+		if feed.CategoryID == nil && feed.SourceKind == model.SourceKindRSS {
+			doc.Body.Outlines = append(doc.Body.Outlines, newOPMLFeedOutline(feed))
+		}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write OPML: %w", err)
+	}
+	return nil
+}
+
+// parseOPML returns an entry for each feed outline of the document, nested folders are flattened
+func parseOPML(r io.Reader) ([]*model.OPMLImportEntry, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = opmlCharsetReader
+	var doc opmlDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOPML, err)
+	}
+
+	entries := []*model.OPMLImportEntry{}
+	collectOPMLEntries(doc.Body.Outlines, "", &entries)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: the file contains no feeds", ErrInvalidOPML)
+	}
+	if len(entries) > maxOPMLFeeds {
+		return nil, fmt.Errorf("%w: the file contains more than %d feeds", ErrInvalidOPML, maxOPMLFeeds)
+	}
+	return entries, nil
+}
+
+func collectOPMLEntries(outlines []*opmlOutline, folder string, entries *[]*model.OPMLImportEntry) {
+	for _, outline := range outlines {
+		name := normalizeName(outline.Title)
+		if name == "" {
+			name = normalizeName(outline.Text)
+		}
+
+		if feedURL := strings.TrimSpace(outline.XMLURL); feedURL != "" {
+			if name == "" {
+				name = feedURL
+			}
+			*entries = append(*entries, &model.OPMLImportEntry{Title: name, URL: feedURL, Category: folder})
+			// outlines nested in a feed are unusual, their feeds stay in the same folder
+			collectOPMLEntries(outline.Outlines, folder, entries)
+			continue
+		}
+
+		subfolder := folder
+		if name != "" {
+			subfolder = name
+		}
+		collectOPMLEntries(outline.Outlines, subfolder, entries)
+	}
+}
+
+// checkEntries marks entries with an invalid URL as invalid, and entries whose URL exists or is listed
+// before as duplicate
+func (s *OPMLService) checkEntries(ctx context.Context, entries []*model.OPMLImportEntry) error {
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if err := s.feedService.validateFeedRequest(entry.Title, entry.URL); err != nil {
+			entry.Status = model.OPMLImportStatusInvalid
+			entry.Error = err.Error()
+			continue
+		}
+		if seen[entry.URL] {
+			entry.Status = model.OPMLImportStatusDuplicate
+			entry.Error = "feed is listed more than once"
+			continue
+		}
+		seen[entry.URL] = true
+
+		exists, err := s.feedService.repo.IsURLExists(ctx, entry.URL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to check for duplicate feed URL: %w", err)
+		}
+		if exists {
+			entry.Status = model.OPMLImportStatusDuplicate
+			entry.Error = ErrDuplicateFeedURL.Error()
+		}
+	}
+	return nil
+}
+
+// validateEntries fetches the remaining entries concurrently and marks those that are not valid feeds as invalid.
+// Entries not validated before ctx is done are marked as cancelled.
+func (s *OPMLService) validateEntries(ctx context.Context, entries []*model.OPMLImportEntry) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opmlValidationWorkers)
+	for _, entry := range entries {
+		if entry.Status != "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// each goroutine only writes its own entry
+			err := s.feedService.validateRSSFeed(ctx, entry.URL)
+			switch {
+			case ctx.Err() != nil:
+				entry.Status = model.OPMLImportStatusCancelled
+				entry.Error = "feed was not validated before the import timed out"
+			case err != nil:
+				entry.Status = model.OPMLImportStatusInvalid
+				entry.Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *OPMLService) createFeed(ctx context.Context, entry *model.OPMLImportEntry) (*model.Feed, error) {
+	now := time.Now()
+	feed := &model.Feed{
+		ID:           uuid.New(),
+		Name:         entry.Title,
+		URL:          entry.URL,
+		SourceKind:   model.SourceKindRSS,
+		SourceConfig: model.SourceConfig{},
+		Weight:       1,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		LastReadAt:   now,
+	}
+	createdFeed, err := s.feedService.repo.Create(ctx, feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feed %s: %w", entry.URL, err)
+	}
+	return createdFeed, nil
+}
+
+// getCategoriesByName returns the categories by their lowercase name, category names are unique regardless of case
+func (s *OPMLService) getCategoriesByName(ctx context.Context) (map[string]*model.Category, error) {
+	categories, err := s.categoryService.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*model.Category, len(categories))
+	for _, category := range categories {
+		byName[strings.ToLower(category.Name)] = category
+	}
+	return byName, nil
+}
+
+// getOrCreateCategory returns the category with the name, creating it if needed. It returns nil if the name
+// is not a valid category name.
+func (s *OPMLService) getOrCreateCategory(ctx context.Context, categories map[string]*model.Category, name string) (*model.Category, error) {
+	if category, ok := categories[strings.ToLower(name)]; ok {
+		return category, nil
+	}
+	category, err := s.categoryService.Create(ctx, &model.CreateCategoryRequest{Name: name})
+	if errors.Is(err, ErrInvalidCategoryName) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	categories[strings.ToLower(category.Name)] = category
+	return category, nil
+}
+
+// appendCategoryFeeds adds the feeds of the imported entries after the current feeds of the category
+func (s *OPMLService) appendCategoryFeeds(ctx context.Context, categoryID uuid.UUID, entries []*model.OPMLImportEntry) error {
+	category, err := s.categoryService.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uuid.UUID, 0, len(category.Feeds)+len(entries))
+	for _, feed := range category.Feeds {
+		ids = append(ids, feed.ID)
+	}
+	for _, entry := range entries {
+		ids = append(ids, *entry.FeedID)
+	}
+	_, err = s.categoryService.SetFeeds(ctx, categoryID, &model.SetCategoryFeedsRequest{FeedIDs: ids})
+	return err
+}
+
+func newOPMLFeedOutline(feed *model.Feed) *opmlOutline {
+	return &opmlOutline{Text: feed.Name, Title: feed.Name, Type: "rss", XMLURL: feed.URL}
+}
+
+// opmlCharsetReader decodes the single-byte charsets used by older OPML files, UTF-8 is decoded by the XML decoder
+func opmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "us-ascii", "ascii":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+}
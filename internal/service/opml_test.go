@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestParseOPML(t *testing.T) {
+	opml := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Loose" type="rss" xmlUrl=" https://example.com/loose.xml "/>
+    <outline text="Subscriptions">
+      <outline text="Tech" title="  Tech   News ">
+        <outline text="Go Blog" type="rss" xmlUrl="https://go.dev/blog/feed.atom"/>
+        <outline text="Deeper">
+          <outline title="Nested" xmlUrl="https://example.com/nested.xml"/>
+        </outline>
+      </outline>
+      <outline xmlUrl="https://example.com/untitled.xml"/>
+    </outline>
+    <outline text="Empty folder"/>
+  </body>
+</opml>`
+
+	entries, err := parseOPML(strings.NewReader(opml))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []*model.OPMLImportEntry{
+		{Title: "Loose", URL: "https://example.com/loose.xml"},
+		{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", Category: "Tech News"},
+		{Title: "Nested", URL: "https://example.com/nested.xml", Category: "Deeper"},
+		{Title: "https://example.com/untitled.xml", URL: "https://example.com/untitled.xml", Category: "Subscriptions"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		for _, entry := range entries {
+			t.Logf("%+v", entry)
+		}
+		t.Errorf("Unexpected entries")
+	}
+}
+
+func TestParseOPML_Latin1(t *testing.T) {
+	opml := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<opml version=\"1.0\"><body>" +
+		"<outline text=\"Caf\xe9\" xmlUrl=\"https://example.com/feed\"/></body></opml>"
+
+	entries, err := parseOPML(strings.NewReader(opml))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(entries) != 1 || entries[0].Title != "Café" {
+		t.Errorf("Expected the title Café, got %+v", entries)
+	}
+}
+
+func TestParseOPML_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opml string
+	}{
+		{"not XML", "feeds"},
+		{"not OPML", `<rss version="2.0"><channel></channel></rss>`},
+		{"no feeds", `<opml version="2.0"><body><outline text="Folder"/></body></opml>`},
+		{"too many feeds", `<opml version="2.0"><body>` + strings.Repeat(`<outline xmlUrl="https://example.com/feed"/>`, maxOPMLFeeds+1) + `</body></opml>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseOPML(strings.NewReader(tt.opml)); !errors.Is(err, ErrInvalidOPML) {
+				t.Errorf("Expected ErrInvalidOPML, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateEntries_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	entries := []*model.OPMLImportEntry{
+		{Title: "Slow feed", URL: server.URL},
+		{Title: "Known feed", URL: server.URL + "/known", Status: model.OPMLImportStatusDuplicate},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	service := &OPMLService{feedService: &FeedService{}}
+	service.validateEntries(ctx, entries)
+
+	if entries[0].Status != model.OPMLImportStatusCancelled {
+		t.Errorf("Expected the slow feed to be cancelled, got %q (%s)", entries[0].Status, entries[0].Error)
+	}
+	if entries[1].Status != model.OPMLImportStatusDuplicate {
+		t.Errorf("Expected the duplicate to keep its status, got %q", entries[1].Status)
+	}
+}
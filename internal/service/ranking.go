@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+const (
+	// rankingWindow limits the candidates of the ranking to recently published articles
+	rankingWindow = 7 * 24 * time.Hour
+	// recencyHalfLife is the age at which the recency signal has dropped to one half
+	recencyHalfLife = 24 * time.Hour
+
+	// weights of the signals, they add up to 1
+	recencyWeight  = 0.5
+	affinityWeight = 0.35
+	dwellWeight    = 0.15
+
+	// savedArticleReads counts a saved article as this many read articles for the feed affinity
+	savedArticleReads = 3
+	// affinityPrior is the number of virtual articles with neutral affinity added to every feed,
+	// so feeds with little history are not ranked by chance
+	affinityPrior   = 10
+	neutralAffinity = 0.2
+	neutralDwell    = 0.5
+)
+
+type RankingService struct {
+	articleRepo *repository.ArticleRepository
+	feedRepo    *repository.FeedRepository
+}
+
+func NewRankingService(articleRepo *repository.ArticleRepository, feedRepo *repository.FeedRepository) *RankingService {
+	return &RankingService{articleRepo: articleRepo, feedRepo: feedRepo}
+}
+
+// ForYou returns up to limit unread articles matching the filter, ranked by their personal score
+func (s *RankingService) ForYou(ctx context.Context, limit int, filter model.ArticleFilter) ([]*model.RankedArticle, error) {
+	if err := validateArticleFilter(filter); err != nil {
+		return nil, err
+	}
+	if filter.Read != nil && *filter.Read {
+		return nil, fmt.Errorf("%w: For You only contains unread articles", ErrInvalidArticleFilter)
+	}
+	if filter.Sort != "" {
+		return nil, fmt.Errorf("%w: For You is ordered by score and cannot be sorted", ErrInvalidArticleFilter)
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+
+	now := time.Now()
+	unread := false
+	filter.Read = &unread
+	if windowStart := now.Add(-rankingWindow); filter.PublishedFrom == nil || filter.PublishedFrom.Before(windowStart) {
+		filter.PublishedFrom = &windowStart
+	}
+
+	engagement, err := s.feedRepo.GetEngagement(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed engagement: %w", err)
+	}
+	byFeed := make(map[uuid.UUID]*model.FeedEngagement, len(engagement))
+	for _, feed := range engagement {
+		byFeed[feed.FeedID] = feed
+	}
+
+	// The database orders the candidates by the same score, so only the best ones are loaded and scored again
+	candidates, err := s.articleRepo.GetRankingCandidates(ctx, filter, newRankingQuery(engagement, now), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ranking candidates: %w", err)
+	}
+
+	ranked := rankArticles(candidates, byFeed, now)
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked, nil
+}
+
+// rankArticles scores the articles and orders them by score, best first.
+// Articles of feeds with a weight of 0 are left out.
+func rankArticles(articles []*model.Article, engagement map[uuid.UUID]*model.FeedEngagement, now time.Time) []*model.RankedArticle {
+	ranked := make([]*model.RankedArticle, 0, len(articles))
+	for _, article := range articles {
+		var feed *model.FeedEngagement
+		if article.FeedID != nil {
+			feed = engagement[*article.FeedID]
+		}
+		score, explanation := scoreArticle(article, feed, now)
+		if explanation.FeedWeight == 0 {
+			continue
+		}
+		ranked = append(ranked, &model.RankedArticle{Article: *article, Score: score, Explanation: explanation})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if !ranked[i].PublishedAt.Equal(ranked[j].PublishedAt) {
+			return ranked[i].PublishedAt.After(ranked[j].PublishedAt)
+		}
+		// Deterministic fallback by ID
+		return ranked[i].ID.String() > ranked[j].ID.String()
+	})
+	for i, article := range ranked {
+		article.Rank = i + 1
+	}
+	return ranked
+}
+
+// scoreArticle returns the score of the article and the signals it is made of.
+// feed is nil for articles without a feed, which get neutral signals.
+func scoreArticle(article *model.Article, feed *model.FeedEngagement, now time.Time) (float64, model.RankingExplanation) {
+	age := max(now.Sub(article.PublishedAt), 0)
+	explanation := model.RankingExplanation{
+		Recency:    math.Pow(0.5, float64(age)/float64(recencyHalfLife)),
+		FeedWeight: 1,
+	}
+	explanation.FeedAffinity, explanation.Dwell = feedSignals(feed)
+
+	type reason struct {
+		contribution float64
+		text         string
+	}
+	reasons := []reason{{recencyWeight * explanation.Recency, "published " + formatAge(age) + " ago"}}
+
+	if feed != nil {
+		explanation.FeedWeight = feed.Weight
+
+		switch {
+		case feed.SavedCount > 0:
+			reasons = append(reasons, reason{affinityWeight * explanation.FeedAffinity,
+				fmt.Sprintf("you read %d and saved %d of %d articles of this feed", feed.ReadCount, feed.SavedCount, feed.ArticleCount)})
+		case feed.ReadCount > 0:
+			reasons = append(reasons, reason{affinityWeight * explanation.FeedAffinity,
+				fmt.Sprintf("you read %d of %d articles of this feed", feed.ReadCount, feed.ArticleCount)})
+		}
+		if feed.DwellCount > 0 {
+			reasons = append(reasons, reason{dwellWeight * explanation.Dwell,
+				fmt.Sprintf("you stay on average %.0f%% of the reading time on articles of this feed", 100*feed.DwellRatio)})
+		}
+	}
+
+	score := explanation.FeedWeight *
+		(recencyWeight*explanation.Recency + affinityWeight*explanation.FeedAffinity + dwellWeight*explanation.Dwell)
+
+	sort.SliceStable(reasons, func(i, j int) bool { return reasons[i].contribution > reasons[j].contribution })
+	if explanation.FeedWeight > 1 {
+		explanation.Reasons = append(explanation.Reasons, fmt.Sprintf("boosted by the feed weight %g", explanation.FeedWeight))
+	} else if explanation.FeedWeight < 1 {
+		explanation.Reasons = append(explanation.Reasons, fmt.Sprintf("lowered by the feed weight %g", explanation.FeedWeight))
+	}
+	for _, r := range reasons {
+		explanation.Reasons = append(explanation.Reasons, r.text)
+	}
+	return score, explanation
+}
+
+// feedSignals returns the feed affinity and dwell signals of the feed, neutral ones if feed is nil
+func feedSignals(feed *model.FeedEngagement) (affinity, dwell float64) {
+	if feed == nil {
+		return neutralAffinity, neutralDwell
+	}
+	engaged := float64(feed.ReadCount + savedArticleReads*feed.SavedCount)
+	affinity = math.Min((engaged+affinityPrior*neutralAffinity)/float64(feed.ArticleCount+affinityPrior), 1)
+	dwell = neutralDwell
+	if feed.DwellCount > 0 {
+		dwell = feed.DwellRatio
+	}
+	return affinity, dwell
+}
+
+// newRankingQuery returns the parts of the score that depend on the feed, so the database can order by the score
+func newRankingQuery(engagement []*model.FeedEngagement, now time.Time) model.RankingQuery {
+	query := model.RankingQuery{
+		Feeds:           make([]*model.FeedScore, len(engagement)),
+		DefaultSignals:  affinityWeight*neutralAffinity + dwellWeight*neutralDwell,
+		RecencyWeight:   recencyWeight,
+		RecencyHalfLife: recencyHalfLife,
+		Now:             now,
+	}
+	for i, feed := range engagement {
+		affinity, dwell := feedSignals(feed)
+		query.Feeds[i] = &model.FeedScore{FeedID: feed.FeedID, Weight: feed.Weight, Signals: affinityWeight*affinity + dwellWeight*dwell}
+	}
+	return query
+}
+
+// formatAge returns the age in minutes, hours or days
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%d minutes", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%d hours", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%d days", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestRankArticles_Recency(t *testing.T) {
+	now := time.Now()
+	older := rankingArticle(nil, now.Add(-30*time.Hour))
+	newer := rankingArticle(nil, now.Add(-2*time.Hour))
+
+	ranked := rankArticles([]*model.Article{older, newer}, nil, now)
+
+	if ranked[0].ID != newer.ID || ranked[0].Rank != 1 || ranked[1].Rank != 2 {
+		t.Errorf("Expected the newer article first, got %v", ranked[0].Title)
+	}
+	if ranked[0].Explanation.Recency <= ranked[1].Explanation.Recency {
+		t.Errorf("Expected higher recency for the newer article")
+	}
+}
+
+func TestRankArticles_FeedAffinity(t *testing.T) {
+	now := time.Now()
+	liked, ignored := uuid.New(), uuid.New()
+	engagement := map[uuid.UUID]*model.FeedEngagement{
+		liked:   {FeedID: liked, Weight: 1, ArticleCount: 40, ReadCount: 30, SavedCount: 5},
+		ignored: {FeedID: ignored, Weight: 1, ArticleCount: 40},
+	}
+	// the article of the ignored feed is a few hours newer
+	fromIgnored := rankingArticle(&ignored, now.Add(-1*time.Hour))
+	fromLiked := rankingArticle(&liked, now.Add(-4*time.Hour))
+
+	ranked := rankArticles([]*model.Article{fromIgnored, fromLiked}, engagement, now)
+
+	if ranked[0].ID != fromLiked.ID {
+		t.Fatalf("Expected the article of the often read feed first")
+	}
+	if !strings.Contains(strings.Join(ranked[0].Explanation.Reasons, "; "), "you read 30 and saved 5 of 40 articles") {
+		t.Errorf("Expected the reading history in the reasons, got %v", ranked[0].Explanation.Reasons)
+	}
+}
+
+func TestRankArticles_FeedWeight(t *testing.T) {
+	now := time.Now()
+	boosted, muted := uuid.New(), uuid.New()
+	engagement := map[uuid.UUID]*model.FeedEngagement{
+		boosted: {FeedID: boosted, Weight: 2},
+		muted:   {FeedID: muted, Weight: 0},
+	}
+	neutral := rankingArticle(nil, now.Add(-1*time.Hour))
+	fromBoosted := rankingArticle(&boosted, now.Add(-12*time.Hour))
+	fromMuted := rankingArticle(&muted, now)
+
+	ranked := rankArticles([]*model.Article{neutral, fromBoosted, fromMuted}, engagement, now)
+
+	if len(ranked) != 2 {
+		t.Fatalf("Expected the muted feed to be left out, got %d articles", len(ranked))
+	}
+	if ranked[0].ID != fromBoosted.ID || ranked[0].Explanation.FeedWeight != 2 {
+		t.Errorf("Expected the boosted article first")
+	}
+	if ranked[0].Explanation.Reasons[0] != "boosted by the feed weight 2" {
+		t.Errorf("Expected the feed weight as first reason, got %v", ranked[0].Explanation.Reasons)
+	}
+}
+
+func TestScoreArticle_NeutralWithoutHistory(t *testing.T) {
+	now := time.Now()
+	feedID := uuid.New()
+	article := rankingArticle(&feedID, now)
+
+	withoutFeed, _ := scoreArticle(article, nil, now)
+	withoutHistory, explanation := scoreArticle(article, &model.FeedEngagement{FeedID: feedID, Weight: 1}, now)
+
+	if withoutFeed != withoutHistory {
+		t.Errorf("Expected a feed without history to score like no feed, got %f and %f", withoutFeed, withoutHistory)
+	}
+	if explanation.FeedAffinity != neutralAffinity || explanation.Dwell != neutralDwell {
+		t.Errorf("Expected neutral signals, got %+v", explanation)
+	}
+}
+
+func rankingArticle(feedID *uuid.UUID, publishedAt time.Time) *model.Article {
+	return &model.Article{
+		ID:          uuid.New(),
+		Title:       publishedAt.Format(time.RFC3339),
+		PublishedAt: publishedAt,
+		FeedID:      feedID,
+	}
+}
+
+func TestNewRankingQuery_MatchesScore(t *testing.T) {
+	now := time.Now()
+	feedID := uuid.New()
+	feed := &model.FeedEngagement{FeedID: feedID, Weight: 2, ArticleCount: 40, ReadCount: 12, SavedCount: 2, DwellCount: 5, DwellRatio: 0.8}
+	query := newRankingQuery([]*model.FeedEngagement{feed}, now)
+
+	for _, article := range []*model.Article{rankingArticle(&feedID, now.Add(-5*time.Hour)), rankingArticle(nil, now.Add(-30*time.Hour))} {
+		weight, signals := 1.0, query.DefaultSignals
+		if article.FeedID != nil {
+			weight, signals = query.Feeds[0].Weight, query.Feeds[0].Signals
+		}
+		score, explanation := scoreArticle(article, byFeedID(article, feed), now)
+		if want := weight * (query.RecencyWeight*explanation.Recency + signals); math.Abs(score-want) > 1e-9 {
+			t.Errorf("Expected the query to order by score %f, got %f", score, want)
+		}
+	}
+}
+
+func byFeedID(article *model.Article, feed *model.FeedEngagement) *model.FeedEngagement {
+	if article.FeedID != nil && *article.FeedID == feed.FeedID {
+		return feed
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+// wordsPerMinute is the average reading speed used for the reading time estimation
+const wordsPerMinute = 200
+
+// countWords returns the number of words of the given HTML or plain text
+func countWords(text string) int {
+	return len(strings.Fields(plainText(text)))
+}
+
+// estimateReadingTime returns the reading time in whole minutes, rounded up
+func estimateReadingTime(wordCount int) int {
+	if wordCount <= 0 {
+		return 0
+	}
+	return (wordCount + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// applyReadingStats sets word count and reading time from the best available text,
+// which is the longer one of the full content and the description.
+func applyReadingStats(article *model.Article) {
+	wordCount := countWords(article.Description)
+	if contentWords := countWords(article.Content); contentWords > wordCount {
+		wordCount = contentWords
+	}
+	article.WordCount = wordCount
+	article.ReadingTime = estimateReadingTime(wordCount)
+}
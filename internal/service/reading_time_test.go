@@ -0,0 +1,61 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestEstimateReadingTime(t *testing.T) {
+	tests := map[int]int{0: 0, 1: 1, 200: 1, 201: 2, 1000: 5}
+	for words, want := range tests {
+		if got := estimateReadingTime(words); got != want {
+			t.Errorf("estimateReadingTime(%d) = %d, want %d", words, got, want)
+		}
+	}
+}
+
+func TestApplyReadingStats_UsesLongestText(t *testing.T) {
+	article := &model.Article{
+		Description: "<p>A short teaser</p>",
+		Content:     "<div>" + strings.Repeat("word ", 450) + "</div>",
+	}
+	applyReadingStats(article)
+
+	if article.WordCount != 450 {
+		t.Errorf("Expected 450 words, got %d", article.WordCount)
+	}
+	if article.ReadingTime != 3 {
+		t.Errorf("Expected reading time of 3 minutes, got %d", article.ReadingTime)
+	}
+}
+
+func TestApplyFetchedContent_RecomputesReadingStats(t *testing.T) {
+	article := &model.Article{Title: "Release notes", Description: "<p>A short teaser</p>"}
+	applyReadingStats(article)
+	article.SimHash = articleFingerprint(article)
+	oldSimHash := article.SimHash
+
+	fetched := &model.Article{
+		Title:       "Release notes",
+		Description: "<p>The full list of changes in this release</p>",
+		Content:     "<div>" + strings.Repeat("word ", 450) + "</div>",
+	}
+	if !applyFetchedContent(article, fetched) {
+		t.Fatal("Expected the changed content to be applied")
+	}
+	if article.Content != fetched.Content || article.Description != fetched.Description {
+		t.Errorf("Expected the fetched content, got %q and %q", article.Description, article.Content)
+	}
+	if article.WordCount != 450 || article.ReadingTime != 3 {
+		t.Errorf("Expected 450 words and 3 minutes, got %d words and %d minutes", article.WordCount, article.ReadingTime)
+	}
+	if article.SimHash == oldSimHash {
+		t.Error("Expected the fingerprint to be recomputed")
+	}
+
+	if applyFetchedContent(article, fetched) {
+		t.Error("Expected unchanged content not to be applied again")
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrInvalidRetentionPolicy  = errors.New("invalid retention policy")
+)
+
+const (
+	// maxRetentionDryRunArticles limits the articles listed by a dry run, the counts include all of them
+	maxRetentionDryRunArticles = 100
+	maxRetentionRuns           = 50
+)
+
+type RetentionService struct {
+	repo     *repository.RetentionRepository
+	feedRepo *repository.FeedRepository
+}
+
+func NewRetentionService(repo *repository.RetentionRepository, feedRepo *repository.FeedRepository) *RetentionService {
+	return &RetentionService{repo: repo, feedRepo: feedRepo}
+}
+
+// GetPolicies returns the global policy first and then the policies of the feeds
+func (s *RetentionService) GetPolicies(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	policies, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+	return policies, nil
+}
+
+func (s *RetentionService) UpdateGlobalPolicy(ctx context.Context, req *model.UpdateRetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	policy, err := validateRetentionPolicy(req)
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.repo.SaveGlobal(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update global retention policy: %w", err)
+	}
+	return saved, nil
+}
+
+// UpdateFeedPolicy sets the policy of the feed, which replaces the global policy for its articles
+func (s *RetentionService) UpdateFeedPolicy(ctx context.Context, feedID uuid.UUID, req *model.UpdateRetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	policy, err := validateRetentionPolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.feedRepo.GetByID(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed with ID %s: %w", feedID, err)
+	}
+
+	saved, err := s.repo.SaveForFeed(ctx, feedID, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update retention policy of feed %s: %w", feedID, err)
+	}
+	return saved, nil
+}
+
+// DeleteFeedPolicy deletes the policy of the feed, its articles use the global policy again
+func (s *RetentionService) DeleteFeedPolicy(ctx context.Context, feedID uuid.UUID) error {
+	found, err := s.repo.DeleteForFeed(ctx, feedID)
+	if err != nil {
+		return fmt.Errorf("failed to delete retention policy of feed %s: %w", feedID, err)
+	}
+	if !found {
+		return ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+// DryRun reports the articles the cleanup would delete now without deleting them
+func (s *RetentionService) DryRun(ctx context.Context) (*model.RetentionDryRunResult, error) {
+	counts, err := s.repo.CountExpiredByFeed(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count expired articles: %w", err)
+	}
+	articles, err := s.repo.GetExpiredArticles(ctx, maxRetentionDryRunArticles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expired articles: %w", err)
+	}
+
+	result := &model.RetentionDryRunResult{Feeds: counts, Articles: articles}
+	for _, count := range counts {
+		result.Count += count.Count
+	}
+	return result, nil
+}
+
+// RunCleanup moves the articles expired by the retention policies to the trash and records the run, failed runs
+// included
+func (s *RetentionService) RunCleanup(ctx context.Context) (*model.RetentionRun, error) {
+	run := &model.RetentionRun{ID: uuid.New(), StartedAt: time.Now(), Feeds: model.RetentionFeedCounts{}}
+	counts, cleanupErr := s.repo.TrashExpiredArticles(ctx)
+	run.FinishedAt = time.Now()
+	if cleanupErr != nil {
+		message := cleanupErr.Error()
+		run.Error = &message
+	} else {
+		run.Feeds = counts
+		for _, count := range counts {
+			run.DeletedCount += count.Count
+		}
+	}
+
+	if _, err := s.repo.CreateRun(ctx, run); err != nil {
+		return nil, errors.Join(cleanupErr, fmt.Errorf("failed to record retention run: %w", err))
+	}
+	if cleanupErr != nil {
+		return nil, fmt.Errorf("failed to trash expired articles: %w", cleanupErr)
+	}
+	return run, nil
+}
+
+// GetRuns returns the latest cleanup runs, newest first
+func (s *RetentionService) GetRuns(ctx context.Context) ([]*model.RetentionRun, error) {
+	runs, err := s.repo.GetRuns(ctx, maxRetentionRuns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention runs: %w", err)
+	}
+	return runs, nil
+}
+
+func validateRetentionPolicy(req *model.UpdateRetentionPolicyRequest) (*model.RetentionPolicy, error) {
+	if req.MaxAgeDays != nil && *req.MaxAgeDays < 1 {
+		return nil, fmt.Errorf("%w: maxAgeDays must be at least 1", ErrInvalidRetentionPolicy)
+	}
+	if req.KeepLatest != nil && *req.KeepLatest < 0 {
+		return nil, fmt.Errorf("%w: keepLatest cannot be negative", ErrInvalidRetentionPolicy)
+	}
+	if req.UnreadMaxAgeDays != nil {
+		if req.MaxAgeDays == nil {
+			return nil, fmt.Errorf("%w: unreadMaxAgeDays requires maxAgeDays", ErrInvalidRetentionPolicy)
+		}
+		if *req.UnreadMaxAgeDays < *req.MaxAgeDays {
+			return nil, fmt.Errorf("%w: unreadMaxAgeDays cannot be less than maxAgeDays", ErrInvalidRetentionPolicy)
+		}
+	}
+	return &model.RetentionPolicy{
+		MaxAgeDays:       req.MaxAgeDays,
+		KeepLatest:       req.KeepLatest,
+		UnreadMaxAgeDays: req.UnreadMaxAgeDays,
+	}, nil
+}
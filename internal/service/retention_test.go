@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		req     model.UpdateRetentionPolicyRequest
+		wantErr bool
+	}{
+		{"keep everything", model.UpdateRetentionPolicyRequest{}, false},
+		{"all settings", model.UpdateRetentionPolicyRequest{MaxAgeDays: days(7), KeepLatest: days(20), UnreadMaxAgeDays: days(30)}, false},
+		{"zero max age", model.UpdateRetentionPolicyRequest{MaxAgeDays: days(0)}, true},
+		{"negative keep latest", model.UpdateRetentionPolicyRequest{MaxAgeDays: days(7), KeepLatest: days(-1)}, true},
+		{"unread without max age", model.UpdateRetentionPolicyRequest{UnreadMaxAgeDays: days(30)}, true},
+		{"unread shorter than max age", model.UpdateRetentionPolicyRequest{MaxAgeDays: days(7), UnreadMaxAgeDays: days(3)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateRetentionPolicy(&tt.req)
+			if tt.wantErr && !errors.Is(err, ErrInvalidRetentionPolicy) {
+				t.Errorf("Expected ErrInvalidRetentionPolicy, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
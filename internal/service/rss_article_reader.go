@@ -4,12 +4,18 @@ import (
 	"context"
 	"crypto/sha256"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lucasg04/fyrss-server/internal/model"
 	"github.com/mmcdole/gofeed"
 )
 
+const rssUserAgent = "Fyrss-Server/1.0 (+https://github.com/LucasG04/fyrss-server)"
+
+// RssArticleReader is the Source implementation for RSS, Atom and JSON feeds.
 type RssArticleReader struct {
 	articleService *ArticleService
 }
@@ -18,14 +24,34 @@ func NewRssArticleReader(articleService *ArticleService) *RssArticleReader {
 	return &RssArticleReader{articleService: articleService}
 }
 
-func (r *RssArticleReader) ReadFeed(ctx context.Context, feed *model.Feed) ([]*model.Article, error) {
+func (r *RssArticleReader) Kind() string {
+	return model.SourceKindRSS
+}
+
+// Validate checks that the URL returns a valid RSS/Atom feed. RSS feeds don't support any config.
+func (r *RssArticleReader) Validate(ctx context.Context, feedURL string, config model.SourceConfig) error {
+	if len(config) > 0 {
+		return fmt.Errorf("%w: rss sources don't accept config", ErrInvalidSourceConfig)
+	}
+	return validateRSSFeedURL(ctx, feedURL)
+}
+
+func (r *RssArticleReader) Fetch(ctx context.Context, feed *model.Feed) ([]*model.Article, FetchMeta, error) {
 	fp := gofeed.NewParser()
+	fp.UserAgent = rssUserAgent
 	rssFeed, err := fp.ParseURLWithContext(feed.URL, ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse feed URL %s: %w", feed.URL, err)
+		return nil, FetchMeta{}, fmt.Errorf("failed to parse feed URL %s: %w", feed.URL, err)
 	}
 	if rssFeed == nil || len(rssFeed.Items) == 0 {
-		return nil, fmt.Errorf("no elements found in feed URL %s", feed.URL)
+		return nil, FetchMeta{}, fmt.Errorf("no elements found in feed URL %s", feed.URL)
+	}
+
+	meta := FetchMeta{
+		Title:    rssFeed.Title,
+		Language: rssFeed.Language,
+		FeedType: rssFeed.FeedType,
+		IconURL:  feedIconURL(rssFeed),
 	}
 
 	feedLength := len(rssFeed.Items)
@@ -35,16 +61,91 @@ func (r *RssArticleReader) ReadFeed(ctx context.Context, feed *model.Feed) ([]*m
 			ID:          uuid.New(),
 			Title:       item.Title,
 			Description: item.Description,
+			Content:     item.Content,
 			ContentHash: generateContentHash(item),
 			SourceUrl:   item.Link,
-			PublishedAt: *item.PublishedParsed,
+			PublishedAt: itemPublishedAt(item),
+			Author:      itemAuthor(item),
+			Categories:  item.Categories,
 			SourceType:  "rss",
 			Save:        false,
 			FeedID:      &feed.ID, // Associate with feed if provided
 		}
 	}
 
-	return articles, nil
+	return articles, meta, nil
+}
+
+// feedIconURL returns the image of the feed and falls back to the favicon of the linked site
+func feedIconURL(rssFeed *gofeed.Feed) string {
+	if rssFeed.Image != nil && rssFeed.Image.URL != "" {
+		return rssFeed.Image.URL
+	}
+	site, err := url.Parse(rssFeed.Link)
+	if err != nil || site.Host == "" || (site.Scheme != "http" && site.Scheme != "https") {
+		return ""
+	}
+	return site.Scheme + "://" + site.Host + "/favicon.ico"
+}
+
+// itemAuthor returns the names of the authors of the item, separated by commas
+func itemAuthor(item *gofeed.Item) string {
+	names := make([]string, 0, len(item.Authors))
+	for _, author := range item.Authors {
+		if author != nil && author.Name != "" {
+			names = append(names, author.Name)
+		}
+	}
+	if len(names) == 0 && item.Author != nil {
+		return item.Author.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// itemPublishedAt falls back to the updated date and then to now if an item has no publish date
+func itemPublishedAt(item *gofeed.Item) time.Time {
+	if item.PublishedParsed != nil {
+		return *item.PublishedParsed
+	}
+	if item.UpdatedParsed != nil {
+		return *item.UpdatedParsed
+	}
+	return time.Now()
+}
+
+// validateRSSFeedURL checks if the given URL returns a valid RSS/Atom feed
+func validateRSSFeedURL(ctx context.Context, feedURL string) error {
+	// Create a context with timeout for the RSS validation
+	validateCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Use gofeed parser to attempt to parse the feed
+	fp := gofeed.NewParser()
+	fp.UserAgent = rssUserAgent
+
+	// Parse the feed URL with context
+	feed, err := fp.ParseURLWithContext(feedURL, validateCtx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRSSFeed, err)
+	}
+
+	// Check if we got a valid feed response
+	if feed == nil {
+		return fmt.Errorf("%w: feed is empty", ErrInvalidRSSFeed)
+	}
+
+	// Check if the feed has a title (basic requirement for valid feeds)
+	if strings.TrimSpace(feed.Title) == "" {
+		return fmt.Errorf("%w: feed has no title", ErrInvalidRSSFeed)
+	}
+
+	// Additional validation: ensure the feed has at least basic structure
+	// We don't require items as some feeds might be empty but still valid
+	if feed.FeedType == "" {
+		return fmt.Errorf("%w: unable to determine feed type", ErrInvalidRSSFeed)
+	}
+
+	return nil
 }
 
 func generateContentHash(item *gofeed.Item) string {
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrRuleNotFound = errors.New("rule not found")
+	ErrInvalidRule  = errors.New("invalid rule")
+)
+
+const (
+	// maxRulePriority bounds the priority set by rules in both directions
+	maxRulePriority = 100
+	// ruleDryRunWindow and ruleDryRunMaxArticles select the recent articles a dry run checks
+	ruleDryRunWindow      = 7 * 24 * time.Hour
+	ruleDryRunMaxArticles = 500
+)
+
+type RuleService struct {
+	repo        *repository.RuleRepository
+	feedRepo    *repository.FeedRepository
+	tagRepo     *repository.TagRepository
+	articleRepo *repository.ArticleRepository
+}
+
+func NewRuleService(repo *repository.RuleRepository, feedRepo *repository.FeedRepository, tagRepo *repository.TagRepository, articleRepo *repository.ArticleRepository) *RuleService {
+	return &RuleService{repo: repo, feedRepo: feedRepo, tagRepo: tagRepo, articleRepo: articleRepo}
+}
+
+// GetAll returns all rules with their match statistics in the order they are applied
+func (s *RuleService) GetAll(ctx context.Context) ([]*model.Rule, error) {
+	rules, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (s *RuleService) GetByID(ctx context.Context, id uuid.UUID) (*model.Rule, error) {
+	rule, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRuleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rule with ID %s: %w", id, err)
+	}
+	return rule, nil
+}
+
+func (s *RuleService) Create(ctx context.Context, req *model.CreateRuleRequest) (*model.Rule, error) {
+	now := time.Now()
+	rule := &model.Rule{
+		ID:         uuid.New(),
+		Name:       strings.TrimSpace(req.Name),
+		FeedID:     req.FeedID,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	createdRule, err := s.repo.Create(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	return createdRule, nil
+}
+
+func (s *RuleService) Update(ctx context.Context, id uuid.UUID, req *model.UpdateRuleRequest) (*model.Rule, error) {
+	rule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.FeedID = req.FeedID
+	rule.Conditions = req.Conditions
+	rule.Actions = req.Actions
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Update(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rule with ID %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrRuleNotFound
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *RuleService) Delete(ctx context.Context, id uuid.UUID) error {
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule with ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrRuleNotFound
+	}
+	return nil
+}
+
+// DryRun checks the rule against the articles published within the last week without changing them
+func (s *RuleService) DryRun(ctx context.Context, req *model.CreateRuleRequest) (*model.RuleDryRunResult, error) {
+	rule := &model.Rule{
+		Name:       strings.TrimSpace(req.Name),
+		FeedID:     req.FeedID,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+		Enabled:    true,
+	}
+	if rule.Name == "" {
+		rule.Name = "dry run"
+	}
+	if err := s.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	compiled, err := compileRule(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	windowStart := time.Now().Add(-ruleDryRunWindow)
+	filter := model.ArticleFilter{PublishedFrom: &windowStart}
+	if rule.FeedID != nil {
+		filter.FeedIDs = []uuid.UUID{*rule.FeedID}
+	}
+	articles, err := s.articleRepo.GetPage(ctx, filter, nil, 0, ruleDryRunMaxArticles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent articles: %w", err)
+	}
+
+	result := &model.RuleDryRunResult{Checked: len(articles), Articles: []*model.Article{}}
+	for _, article := range articles {
+		if compiled.matches(article) {
+			result.Articles = append(result.Articles, article)
+		}
+	}
+	result.Matched = len(result.Articles)
+	return result, nil
+}
+
+// RulesForFeed returns the enabled rules which apply to the feed, ready to be applied to its new articles
+func (s *RuleService) RulesForFeed(ctx context.Context, feedID uuid.UUID) (*RuleSet, error) {
+	rules, err := s.repo.GetEnabledByFeedID(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rules of feed %s: %w", feedID, err)
+	}
+	set := &RuleSet{matches: make(map[uuid.UUID]int)}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			// rules are validated when saved, so this only happens for rules changed in the database
+			fmt.Printf("Skipping invalid rule '%s' (%s): %v\n", rule.Name, rule.ID, err)
+			continue
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+// ApplyTags adds the tags of a rule outcome to the saved article. A failing tag doesn't keep the others from being added.
+func (s *RuleService) ApplyTags(ctx context.Context, articleID uuid.UUID, tagIDs []uuid.UUID) error {
+	var errs []error
+	for _, tagID := range tagIDs {
+		if err := s.tagRepo.AddToArticle(ctx, articleID, tagID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to tag article %s: %w", articleID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RecordMatches adds the matches of the rule set to the statistics of its rules
+func (s *RuleService) RecordMatches(ctx context.Context, set *RuleSet) error {
+	if err := s.repo.AddMatches(ctx, set.matches); err != nil {
+		return fmt.Errorf("failed to record rule matches: %w", err)
+	}
+	return nil
+}
+
+func (s *RuleService) validateRule(ctx context.Context, rule *model.Rule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidRule)
+	}
+	if len(rule.Conditions) == 0 {
+		return fmt.Errorf("%w: at least one condition is required", ErrInvalidRule)
+	}
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("%w: at least one action is required", ErrInvalidRule)
+	}
+	if _, err := compileRule(rule); err != nil {
+		return err
+	}
+	if err := validateRuleActions(rule.Actions); err != nil {
+		return err
+	}
+
+	if rule.FeedID != nil {
+		if _, err := s.feedRepo.GetByID(ctx, *rule.FeedID); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: feed %s not found", ErrInvalidRule, *rule.FeedID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get feed with ID %s: %w", *rule.FeedID, err)
+		}
+	}
+	for _, action := range rule.Actions {
+		if action.Type != model.RuleActionTag {
+			continue
+		}
+		if _, err := s.tagRepo.GetByID(ctx, *action.TagID); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: tag %s not found", ErrInvalidRule, *action.TagID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get tag with ID %s: %w", *action.TagID, err)
+		}
+	}
+	return nil
+}
+
+// validateRuleActions checks that every action is known and has its parameter
+func validateRuleActions(actions model.RuleActions) error {
+	for _, action := range actions {
+		switch action.Type {
+		case model.RuleActionSkip, model.RuleActionMarkRead, model.RuleActionSave:
+		case model.RuleActionTag:
+			if action.TagID == nil {
+				return fmt.Errorf("%w: the tag action requires a tagId", ErrInvalidRule)
+			}
+		case model.RuleActionSetPriority:
+			if action.Priority == nil {
+				return fmt.Errorf("%w: the setPriority action requires a priority", ErrInvalidRule)
+			}
+			if *action.Priority < -maxRulePriority || *action.Priority > maxRulePriority {
+				return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidRule, -maxRulePriority, maxRulePriority)
+			}
+		default:
+			return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, action.Type)
+		}
+	}
+	return nil
+}
+
+// RuleSet holds the compiled rules of a feed and counts their matches during one ingestion run
+type RuleSet struct {
+	rules   []*compiledRule
+	matches map[uuid.UUID]int
+}
+
+// RuleOutcome is the result of applying a rule set to a new article
+type RuleOutcome struct {
+	// Skip is true if the article must not be saved
+	Skip bool
+	// TagIDs are the tags to add once the article is saved
+	TagIDs []uuid.UUID
+}
+
+// Empty reports whether there are no rules to apply
+func (s *RuleSet) Empty() bool {
+	return len(s.rules) == 0
+}
+
+// Apply applies the actions of all matching rules in order to the unsaved article.
+// A matching skip rule stops the evaluation.
+func (s *RuleSet) Apply(article *model.Article, now time.Time) RuleOutcome {
+	var outcome RuleOutcome
+	for _, rule := range s.rules {
+		if !rule.matches(article) {
+			continue
+		}
+		s.matches[rule.rule.ID]++
+		for _, action := range rule.rule.Actions {
+			switch action.Type {
+			case model.RuleActionSkip:
+				outcome.Skip = true
+			case model.RuleActionMarkRead:
+				readAt := now
+				article.LastReadAt = &readAt
+			case model.RuleActionSave:
+				article.Save = true
+			case model.RuleActionTag:
+				outcome.TagIDs = append(outcome.TagIDs, *action.TagID)
+			case model.RuleActionSetPriority:
+				// later rules override the priority of earlier rules
+				article.Priority = *action.Priority
+			}
+		}
+		if outcome.Skip {
+			return RuleOutcome{Skip: true}
+		}
+	}
+	return outcome
+}
+
+type compiledRule struct {
+	rule       *model.Rule
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	field model.RuleField
+	match func(value string) bool
+}
+
+// compileRule prepares the conditions of the rule for matching
+func compileRule(rule *model.Rule) (*compiledRule, error) {
+	compiled := &compiledRule{rule: rule}
+	for _, condition := range rule.Conditions {
+		switch condition.Field {
+		case model.RuleFieldTitle, model.RuleFieldContent, model.RuleFieldAuthor, model.RuleFieldCategory:
+		default:
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidRule, condition.Field)
+		}
+
+		var match func(value string) bool
+		switch condition.Match {
+		case model.RuleMatchKeyword:
+			keyword := strings.ToLower(strings.TrimSpace(condition.Value))
+			if keyword == "" {
+				return nil, fmt.Errorf("%w: keyword cannot be empty", ErrInvalidRule)
+			}
+			match = func(value string) bool { return strings.Contains(strings.ToLower(value), keyword) }
+		case model.RuleMatchRegex:
+			re, err := regexp.Compile(condition.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid regex %q: %v", ErrInvalidRule, condition.Value, err)
+			}
+			match = re.MatchString
+		default:
+			return nil, fmt.Errorf("%w: unknown match %q", ErrInvalidRule, condition.Match)
+		}
+		compiled.conditions = append(compiled.conditions, compiledCondition{field: condition.Field, match: match})
+	}
+	return compiled, nil
+}
+
+// matches reports whether the article matches all conditions of the rule.
+// A condition on a field with several values matches if it matches any of them.
+func (r *compiledRule) matches(article *model.Article) bool {
+	for _, condition := range r.conditions {
+		if !slices.ContainsFunc(ruleFieldValues(article, condition.field), condition.match) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleFieldValues returns the values of the field
+func ruleFieldValues(article *model.Article, field model.RuleField) []string {
+	switch field {
+	case model.RuleFieldTitle:
+		return []string{article.Title}
+	case model.RuleFieldContent:
+		return []string{article.Description, article.Content}
+	case model.RuleFieldAuthor:
+		return []string{article.Author}
+	case model.RuleFieldCategory:
+		return article.Categories
+	default:
+		return nil
+	}
+}
+
+// TagRewrite returns the rewrite of the rules tagging with the source tags when they are deleted, or merged
+// into the target tag if targetID is not nil. Rules left without actions are disabled, so ingestion never tags
+// with a missing tag.
+func (s *RuleService) TagRewrite(sourceIDs []uuid.UUID, targetID *uuid.UUID) repository.RuleRewrite {
+	return func(rule *model.Rule) {
+		rule.Actions = replaceRuleTagActions(rule.Actions, sourceIDs, targetID)
+		if len(rule.Actions) == 0 {
+			rule.Enabled = false
+		}
+	}
+}
+
+// replaceRuleTagActions returns the actions with the tag actions of the source tags pointing to the target tag,
+// or without them if targetID is nil. Tag actions which end up with the same tag are merged.
+func replaceRuleTagActions(actions model.RuleActions, sourceIDs []uuid.UUID, targetID *uuid.UUID) model.RuleActions {
+	replaced := model.RuleActions{}
+	tagged := make(map[uuid.UUID]bool)
+	for _, action := range actions {
+		if action.Type == model.RuleActionTag && action.TagID != nil {
+			if slices.Contains(sourceIDs, *action.TagID) {
+				if targetID == nil {
+					continue
+				}
+				action.TagID = targetID
+			}
+			if tagged[*action.TagID] {
+				continue
+			}
+			tagged[*action.TagID] = true
+		}
+		replaced = append(replaced, action)
+	}
+	return replaced
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestCompileRule_Matches(t *testing.T) {
+	article := &model.Article{
+		Title:       "Champions League: Results of the evening",
+		Description: "A sponsored post about our product",
+		Author:      "Jane Doe",
+		Categories:  []string{"Sport", "Football"},
+	}
+
+	tests := []struct {
+		name      string
+		condition model.RuleCondition
+		want      bool
+	}{
+		{"keyword ignores case", model.RuleCondition{Field: model.RuleFieldTitle, Match: model.RuleMatchKeyword, Value: "RESULTS"}, true},
+		{"keyword in content", model.RuleCondition{Field: model.RuleFieldContent, Match: model.RuleMatchKeyword, Value: "sponsored"}, true},
+		{"regex", model.RuleCondition{Field: model.RuleFieldTitle, Match: model.RuleMatchRegex, Value: `^Champions\s+League`}, true},
+		{"any category", model.RuleCondition{Field: model.RuleFieldCategory, Match: model.RuleMatchKeyword, Value: "football"}, true},
+		{"author", model.RuleCondition{Field: model.RuleFieldAuthor, Match: model.RuleMatchKeyword, Value: "john"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := compileRule(&model.Rule{Conditions: model.RuleConditions{tt.condition}})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := rule.matches(article); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("all conditions must match", func(t *testing.T) {
+		rule, _ := compileRule(&model.Rule{Conditions: model.RuleConditions{
+			{Field: model.RuleFieldTitle, Match: model.RuleMatchKeyword, Value: "results"},
+			{Field: model.RuleFieldAuthor, Match: model.RuleMatchKeyword, Value: "john"},
+		}})
+		if rule.matches(article) {
+			t.Errorf("Expected no match if one condition doesn't match")
+		}
+	})
+}
+
+func TestCompileRule_Invalid(t *testing.T) {
+	conditions := []model.RuleCondition{
+		{Field: "url", Match: model.RuleMatchKeyword, Value: "sport"},
+		{Field: model.RuleFieldTitle, Match: "glob", Value: "sport*"},
+		{Field: model.RuleFieldTitle, Match: model.RuleMatchKeyword, Value: "  "},
+		{Field: model.RuleFieldTitle, Match: model.RuleMatchRegex, Value: "(unclosed"},
+	}
+	for _, condition := range conditions {
+		_, err := compileRule(&model.Rule{Conditions: model.RuleConditions{condition}})
+		if !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Expected ErrInvalidRule for %+v, got %v", condition, err)
+		}
+	}
+}
+
+func TestValidateRuleActions(t *testing.T) {
+	priority := 200
+	invalid := []model.RuleAction{
+		{Type: "archive"},
+		{Type: model.RuleActionTag},
+		{Type: model.RuleActionSetPriority},
+		{Type: model.RuleActionSetPriority, Priority: &priority},
+	}
+	for _, action := range invalid {
+		if err := validateRuleActions(model.RuleActions{action}); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Expected ErrInvalidRule for %+v, got %v", action, err)
+		}
+	}
+}
+
+func TestRuleSet_Apply(t *testing.T) {
+	now := time.Now()
+	tagID := uuid.New()
+	priority := 5
+	product := ruleSetRule(t, "fyrss",
+		model.RuleAction{Type: model.RuleActionSave},
+		model.RuleAction{Type: model.RuleActionTag, TagID: &tagID},
+		model.RuleAction{Type: model.RuleActionSetPriority, Priority: &priority})
+	sponsored := ruleSetRule(t, "sponsored", model.RuleAction{Type: model.RuleActionSkip})
+	set := &RuleSet{rules: []*compiledRule{product, sponsored}, matches: make(map[uuid.UUID]int)}
+
+	article := &model.Article{Title: "Fyrss 2.0 released"}
+	outcome := set.Apply(article, now)
+	if outcome.Skip || !article.Save || article.Priority != 5 || len(outcome.TagIDs) != 1 || outcome.TagIDs[0] != tagID {
+		t.Errorf("Expected the article to be saved, tagged and prioritized, got %+v and %+v", outcome, article)
+	}
+
+	skipped := &model.Article{Title: "Sponsored: Fyrss"}
+	if outcome := set.Apply(skipped, now); !outcome.Skip || len(outcome.TagIDs) != 0 {
+		t.Errorf("Expected the sponsored article to be skipped without tags, got %+v", outcome)
+	}
+
+	if set.matches[product.rule.ID] != 2 || set.matches[sponsored.rule.ID] != 1 {
+		t.Errorf("Expected the matches to be counted, got %v", set.matches)
+	}
+}
+
+func TestRuleSet_ApplyMarkRead(t *testing.T) {
+	now := time.Now()
+	set := &RuleSet{
+		rules:   []*compiledRule{ruleSetRule(t, "results", model.RuleAction{Type: model.RuleActionMarkRead})},
+		matches: make(map[uuid.UUID]int),
+	}
+
+	article := &model.Article{Title: "Results of the evening"}
+	set.Apply(article, now)
+	if article.LastReadAt == nil || !article.LastReadAt.Equal(now) {
+		t.Errorf("Expected the article to be marked as read, got %v", article.LastReadAt)
+	}
+}
+
+func ruleSetRule(t *testing.T, keyword string, actions ...model.RuleAction) *compiledRule {
+	t.Helper()
+	rule, err := compileRule(&model.Rule{
+		ID:         uuid.New(),
+		Conditions: model.RuleConditions{{Field: model.RuleFieldTitle, Match: model.RuleMatchKeyword, Value: keyword}},
+		Actions:    actions,
+	})
+	if err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+	return rule
+}
+
+// memoryArticleStore keeps saved and skipped articles by content hash
+type memoryArticleStore struct {
+	hashes map[string]bool
+}
+
+func (m *memoryArticleStore) GetFetchedBefore(ctx context.Context, feedID uuid.UUID, fetched *model.Article) (*model.Article, error) {
+	return nil, nil
+}
+
+func (m *memoryArticleStore) UpdateContent(ctx context.Context, article, fetched *model.Article) (bool, error) {
+	return false, nil
+}
+
+func (m *memoryArticleStore) IsDuplicate(ctx context.Context, article *model.Article) (bool, error) {
+	return m.hashes[article.ContentHash], nil
+}
+
+func (m *memoryArticleStore) Save(ctx context.Context, article *model.Article) error {
+	if m.hashes[article.ContentHash] {
+		return ErrDuplicateArticle
+	}
+	m.hashes[article.ContentHash] = true
+	return nil
+}
+
+func (m *memoryArticleStore) SaveSkipped(ctx context.Context, article *model.Article) error {
+	m.hashes[article.ContentHash] = true
+	return nil
+}
+
+func TestIngestArticles_SkippedArticleMatchesOnce(t *testing.T) {
+	rule := &model.Rule{
+		ID:         uuid.New(),
+		Conditions: model.RuleConditions{{Field: model.RuleFieldTitle, Match: model.RuleMatchKeyword, Value: "sponsored"}},
+		Actions:    model.RuleActions{{Type: model.RuleActionSkip}},
+	}
+	compiled, err := compileRule(rule)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	rules := &RuleSet{rules: []*compiledRule{compiled}, matches: make(map[uuid.UUID]int)}
+	store := &memoryArticleStore{hashes: make(map[string]bool)}
+	feed := &model.Feed{ID: uuid.New()}
+
+	for fetch := 1; fetch <= 2; fetch++ {
+		articles := []*model.Article{
+			{Title: "Sponsored: our product", ContentHash: "skipped"},
+			{Title: "Election results", ContentHash: "saved"},
+		}
+		saved, stats := ingestArticles(context.Background(), store, feed, articles, rules, time.Now())
+		wantSaved, wantSkipped, wantDuplicates := 1, 1, 0
+		if fetch == 2 {
+			wantSaved, wantSkipped, wantDuplicates = 0, 0, 2
+		}
+		if len(saved) != wantSaved || stats.ruleSkipped != wantSkipped || stats.duplicates != wantDuplicates {
+			t.Errorf("Fetch %d: expected %d saved, %d skipped and %d duplicates, got %d, %d and %d",
+				fetch, wantSaved, wantSkipped, wantDuplicates, len(saved), stats.ruleSkipped, stats.duplicates)
+		}
+	}
+
+	if got := rules.matches[rule.ID]; got != 1 {
+		t.Errorf("Expected the skip rule to match once, got %d", got)
+	}
+}
+
+func TestRuleService_TagRewrite(t *testing.T) {
+	s := &RuleService{}
+	deleted, kept, target := uuid.New(), uuid.New(), uuid.New()
+	priority := 3
+	actions := model.RuleActions{
+		{Type: model.RuleActionTag, TagID: &deleted},
+		{Type: model.RuleActionSetPriority, Priority: &priority},
+		{Type: model.RuleActionTag, TagID: &kept},
+		{Type: model.RuleActionTag, TagID: &target},
+	}
+
+	t.Run("deleted tag is removed", func(t *testing.T) {
+		rule := &model.Rule{Actions: actions, Enabled: true}
+		s.TagRewrite([]uuid.UUID{deleted}, nil)(rule)
+		want := model.RuleActions{actions[1], actions[2], actions[3]}
+		if !reflect.DeepEqual(rule.Actions, want) || !rule.Enabled {
+			t.Errorf("Expected enabled rule with %+v, got %+v", want, rule)
+		}
+	})
+
+	t.Run("merged tags point to the target once", func(t *testing.T) {
+		rule := &model.Rule{Actions: actions, Enabled: true}
+		s.TagRewrite([]uuid.UUID{deleted, kept}, &target)(rule)
+		want := model.RuleActions{{Type: model.RuleActionTag, TagID: &target}, actions[1]}
+		if !reflect.DeepEqual(rule.Actions, want) {
+			t.Errorf("Expected %+v, got %+v", want, rule.Actions)
+		}
+	})
+
+	t.Run("rule with only the deleted tag is disabled", func(t *testing.T) {
+		rule := &model.Rule{Actions: actions[:1], Enabled: true}
+		s.TagRewrite([]uuid.UUID{deleted}, nil)(rule)
+		if len(rule.Actions) != 0 || rule.Enabled {
+			t.Errorf("Expected disabled rule without actions, got %+v", rule)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// sanitizedElements are the elements kept by the sanitizer, all others are replaced by their content
+var sanitizedElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "blockquote": true, "br": true, "cite": true, "code": true, "dd": true,
+	"del": true, "div": true, "dl": true, "dt": true, "em": true, "figcaption": true, "figure": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "i": true, "img": true, "ins": true,
+	"li": true, "mark": true, "ol": true, "p": true, "pre": true, "q": true, "s": true, "small": true, "span": true,
+	"strong": true, "sub": true, "sup": true, "table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "tr": true, "u": true, "ul": true,
+}
+
+// droppedElements are removed together with their content
+var droppedElements = map[string]bool{
+	"audio": true, "button": true, "canvas": true, "embed": true, "form": true, "iframe": true, "input": true,
+	"link": true, "math": true, "meta": true, "noscript": true, "object": true, "script": true, "select": true,
+	"style": true, "svg": true, "template": true, "textarea": true, "title": true, "video": true,
+}
+
+// parseArticleHTML parses the content of an article as a fragment of a body element
+func parseArticleHTML(content string) []*html.Node {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		// the parser only fails on read errors, which a string reader does not return
+		return []*html.Node{{Type: html.TextNode, Data: plainText(content)}}
+	}
+	return nodes
+}
+
+// sanitizedImageURLs returns the absolute http(s) URLs of the images kept by the sanitizer
+func sanitizedImageURLs(nodes []*html.Node, base *url.URL) []string {
+	var urls []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && droppedElements[n.Data] {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "img" {
+			if src := resolveSanitizedURL(attribute(n, "src"), base, false); src != "" {
+				urls = append(urls, src)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return urls
+}
+
+// renderSanitizedXHTML renders the nodes as XHTML with only the sanitized elements and attributes.
+// Links are made absolute, images are replaced by the local path in images or by their alt text.
+// If images is nil, images keep their absolute remote URL.
+func renderSanitizedXHTML(nodes []*html.Node, base *url.URL, images map[string]string) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		renderSanitizedNode(&b, n, base, images)
+	}
+	return b.String()
+}
+
+func renderSanitizedNode(b *strings.Builder, n *html.Node, base *url.URL, images map[string]string) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(escapeXML(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			renderSanitizedNode(b, child, base, images)
+		}
+		return
+	}
+
+	if droppedElements[n.Data] {
+		return
+	}
+	if !sanitizedElements[n.Data] {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			renderSanitizedNode(b, child, base, images)
+		}
+		return
+	}
+
+	switch n.Data {
+	case "img":
+		src := resolveSanitizedURL(attribute(n, "src"), base, false)
+		if images != nil {
+			src = images[src]
+		}
+		alt := attribute(n, "alt")
+		if src == "" {
+			b.WriteString(escapeXML(alt))
+			return
+		}
+		b.WriteString(`<img src="` + escapeXML(src) + `" alt="` + escapeXML(alt) + `"/>`)
+		return
+	case "br", "hr":
+		b.WriteString("<" + n.Data + "/>")
+		return
+	}
+
+	b.WriteString("<" + n.Data)
+	switch n.Data {
+	case "a":
+		if href := resolveSanitizedURL(attribute(n, "href"), base, true); href != "" {
+			b.WriteString(` href="` + escapeXML(href) + `"`)
+		}
+	case "td", "th":
+		for _, name := range []string{"colspan", "rowspan"} {
+			if value := attribute(n, name); value != "" && strings.Trim(value, "0123456789") == "" {
+				b.WriteString(" " + name + `="` + value + `"`)
+			}
+		}
+	}
+	b.WriteString(">")
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		renderSanitizedNode(b, child, base, images)
+	}
+	b.WriteString("</" + n.Data + ">")
+}
+
+// resolveSanitizedURL resolves the reference against the base and returns it if it is an http(s) URL,
+// or a mailto URL if links is true. Other URLs are dropped.
+func resolveSanitizedURL(ref string, base *url.URL, links bool) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	switch {
+	case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+		return u.String()
+	case links && u.Scheme == "mailto":
+		return u.String()
+	default:
+		return ""
+	}
+}
+
+func attribute(n *html.Node, name string) string {
+	for _, attr := range n.Attr {
+		if attr.Namespace == "" && attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// escapeXML escapes s for XML text and attribute values and removes the characters XML does not allow
+func escapeXML(s string) string {
+	valid := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF && r != utf8.RuneError) {
+			return r
+		}
+		return -1
+	}, s)
+	return html.EscapeString(valid)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestRenderSanitizedXHTML(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain text", "Tom & Jerry <3", "Tom &amp; Jerry &lt;3"},
+		{"allowed elements", "<p>A <b>bold</b><br>line</p>", "<p>A <b>bold</b><br/>line</p>"},
+		{"unknown elements are unwrapped", `<section class="x"><p style="color: red">Hi</p></section>`, "<p>Hi</p>"},
+		{"scripts are dropped", "<p>Hi</p><script>alert(1)</script><iframe src=\"x\">frame</iframe>", "<p>Hi</p>"},
+		{"relative links are resolved", `<a href="/about" onclick="x()">About</a>`, `<a href="https://example.com/about">About</a>`},
+		{"unsafe links are dropped", `<a href="javascript:alert(1)">Click</a>`, "<a>Click</a>"},
+		{"unclosed elements are closed", "<ul><li>One<li>Two</ul><p>Open", "<ul><li>One</li><li>Two</li></ul><p>Open</p>"},
+		{"table spans", `<table><tr><td colspan="2" rowspan="x">A</td></tr></table>`, `<table><tbody><tr><td colspan="2">A</td></tr></tbody></table>`},
+		{"embedded image", `<img src="img/a.png" alt="A" width="10">`, `<img src="../images/image-1.png" alt="A"/>`},
+		{"missing image keeps alt text", `<img src="https://cdn.example.com/b.png" alt="B">`, "B"},
+		{"invalid XML characters", "a\x00b\x0cc", "abc"},
+	}
+	images := map[string]string{"https://example.com/posts/img/a.png": "../images/image-1.png"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderSanitizedXHTML(parseArticleHTML(tt.content), base, images); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSanitizedImageURLs(t *testing.T) {
+	base, _ := url.Parse("https://example.com/posts/1")
+	content := `<p><img src="a.png"><img src="data:image/png;base64,AAAA"></p>` +
+		`<noscript><img src="hidden.png"></noscript><img src="https://cdn.example.com/b.jpg"><img>`
+
+	got := sanitizedImageURLs(parseArticleHTML(content), base)
+	want := []string{"https://example.com/posts/a.png", "https://cdn.example.com/b.jpg"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrShareNotFound = errors.New("share not found")
+	ErrInvalidShare  = errors.New("invalid share")
+)
+
+const (
+	// shareTokenBytes is the number of random bytes of a share token, encoded as 32 URL-safe characters
+	shareTokenBytes = 24
+	// maxSharedArticles is the maximum number of articles shown for a shared collection
+	maxSharedArticles = 500
+)
+
+type ShareService struct {
+	repo           *repository.ShareRepository
+	articleRepo    *repository.ArticleRepository
+	collectionRepo *repository.CollectionRepository
+	feedRepo       *repository.FeedRepository
+}
+
+func NewShareService(repo *repository.ShareRepository, articleRepo *repository.ArticleRepository, collectionRepo *repository.CollectionRepository, feedRepo *repository.FeedRepository) *ShareService {
+	return &ShareService{repo: repo, articleRepo: articleRepo, collectionRepo: collectionRepo, feedRepo: feedRepo}
+}
+
+// GetActive returns the shares that have not expired, newest first
+func (s *ShareService) GetActive(ctx context.Context) ([]*model.Share, error) {
+	shares, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shares: %w", err)
+	}
+	for _, share := range shares {
+		share.URL = shareURL(share.Token)
+	}
+	return shares, nil
+}
+
+// Create creates a share with a random token for an article or a collection
+func (s *ShareService) Create(ctx context.Context, req *model.CreateShareRequest) (*model.Share, error) {
+	if (req.ArticleID == nil) == (req.CollectionID == nil) {
+		return nil, fmt.Errorf("%w: either articleId or collectionId must be set", ErrInvalidShare)
+	}
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expiresAt must be in the future", ErrInvalidShare)
+	}
+
+	if req.ArticleID != nil {
+		_, err := s.articleRepo.GetByID(ctx, *req.ArticleID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrArticleNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get article with ID %s: %w", *req.ArticleID, err)
+		}
+	} else {
+		_, err := s.collectionRepo.GetByID(ctx, *req.CollectionID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCollectionNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get collection with ID %s: %w", *req.CollectionID, err)
+		}
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	share := &model.Share{
+		ID:           uuid.New(),
+		Token:        token,
+		ArticleID:    req.ArticleID,
+		CollectionID: req.CollectionID,
+		CreatedAt:    now,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if err := s.repo.Create(ctx, share); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.GetByID(ctx, share.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created share: %w", err)
+	}
+	created.URL = shareURL(created.Token)
+	return created, nil
+}
+
+// Revoke deletes the share, its link stops working immediately
+func (s *ShareService) Revoke(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke share %s: %w", id, err)
+	}
+	if !deleted {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// GetContent returns the public view of the share with the token. Unknown and expired tokens and
+// shared articles that were moved to the trash are not found.
+func (s *ShareService) GetContent(ctx context.Context, token string) (*model.SharedContent, error) {
+	share, err := s.repo.GetActiveByToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	var articles []*model.Article
+	if share.ArticleID != nil {
+		article, err := s.articleRepo.GetByID(ctx, *share.ArticleID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shared article: %w", err)
+		}
+		articles = []*model.Article{article}
+	} else {
+		collectionArticles, err := s.collectionRepo.GetArticles(ctx, *share.CollectionID, nil, maxSharedArticles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shared collection: %w", err)
+		}
+		for _, article := range collectionArticles {
+			articles = append(articles, &article.Article)
+		}
+	}
+
+	feeds, err := s.getFeedNames(ctx, articles)
+	if err != nil {
+		return nil, err
+	}
+	content := &model.SharedContent{Title: share.Title, ExpiresAt: share.ExpiresAt, Articles: make([]*model.SharedArticle, len(articles))}
+	for i, article := range articles {
+		content.Articles[i] = newSharedArticle(article, feeds)
+	}
+	return content, nil
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>body { max-width: 42em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.5; } img { max-width: 100%; height: auto; } .meta { color: #666; }</style>
+</head>
+<body>
+{{if gt (len .Articles) 1}}<h1>{{.Title}}</h1>
+{{end}}{{range .Articles}}<article>
+<h2><a href="{{.SourceUrl}}" rel="noopener noreferrer">{{.Title}}</a></h2>
+<p class="meta">{{if .Feed}}{{.Feed}} · {{end}}{{if .Author}}{{.Author}} · {{end}}{{.PublishedAt.Format "January 2, 2006"}}</p>
+{{if .Content}}{{.Content}}{{else if .Description}}<p>{{.Description}}</p>{{end}}
+</article>
+{{else}}<p>Nothing has been shared here yet.</p>
+{{end}}</body>
+</html>
+`))
+
+// WritePage writes the content as a minimal HTML page. The content of the articles was sanitized when
+// the content was loaded, so it is not escaped again.
+func (s *ShareService) WritePage(w io.Writer, content *model.SharedContent) error {
+	type pageArticle struct {
+		*model.SharedArticle
+		Content template.HTML
+	}
+	articles := make([]pageArticle, len(content.Articles))
+	for i, article := range content.Articles {
+		articles[i] = pageArticle{SharedArticle: article, Content: template.HTML(article.Content)}
+	}
+	return sharePageTemplate.Execute(w, map[string]any{"Title": content.Title, "Articles": articles})
+}
+
+func (s *ShareService) getFeedNames(ctx context.Context, articles []*model.Article) (map[uuid.UUID]*model.ArticleFeed, error) {
+	var ids []uuid.UUID
+	for _, article := range articles {
+		if article.FeedID != nil {
+			ids = append(ids, *article.FeedID)
+		}
+	}
+	feeds, err := s.feedRepo.GetArticleFeedsByIDs(ctx, uniqueValues(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeds of shared articles: %w", err)
+	}
+	return feeds, nil
+}
+
+// newSharedArticle copies the public fields of the article, its content is sanitized with remote images
+func newSharedArticle(article *model.Article, feeds map[uuid.UUID]*model.ArticleFeed) *model.SharedArticle {
+	shared := &model.SharedArticle{
+		Title:       article.Title,
+		SourceUrl:   resolveSanitizedURL(article.SourceUrl, nil, false),
+		Author:      strings.TrimSpace(article.Author),
+		PublishedAt: article.PublishedAt,
+		Description: plainText(article.Description),
+	}
+	if article.FeedID != nil && feeds[*article.FeedID] != nil {
+		shared.Feed = feeds[*article.FeedID].Name
+	}
+	if strings.TrimSpace(article.Content) != "" {
+		base, _ := url.Parse(article.SourceUrl)
+		shared.Content = renderSanitizedXHTML(parseArticleHTML(article.Content), base, nil)
+	}
+	return shared
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func shareURL(token string) string {
+	return "/s/" + token
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestShareService_Create_Invalid(t *testing.T) {
+	id := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name string
+		req  model.CreateShareRequest
+	}{
+		{"no target", model.CreateShareRequest{}},
+		{"article and collection", model.CreateShareRequest{ArticleID: &id, CollectionID: &id}},
+		{"expired", model.CreateShareRequest{ArticleID: &id, ExpiresAt: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := (&ShareService{}).Create(context.Background(), &tt.req); !errors.Is(err, ErrInvalidShare) {
+				t.Errorf("Expected ErrInvalidShare, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewSharedArticle(t *testing.T) {
+	feedID := uuid.New()
+	feeds := map[uuid.UUID]*model.ArticleFeed{feedID: {ID: feedID, Name: "Example News"}}
+	article := &model.Article{
+		Title:       "Budget",
+		SourceUrl:   "https://example.com/posts/budget",
+		FeedID:      &feedID,
+		Author:      " Jane ",
+		Description: "<p>The budget <b>grows</b> again</p>",
+		Content:     `<p onclick="x()">Text <img src="chart.png" alt="Chart"></p><script>alert(1)</script>`,
+		Note:        "private",
+	}
+
+	shared := newSharedArticle(article, feeds)
+	if shared.Feed != "Example News" || shared.Author != "Jane" || shared.Description != "The budget grows again" {
+		t.Errorf("Unexpected shared article %+v", shared)
+	}
+	want := `<p>Text <img src="https://example.com/posts/chart.png" alt="Chart"/></p>`
+	if shared.Content != want {
+		t.Errorf("Expected content %q, got %q", want, shared.Content)
+	}
+
+	if shared := newSharedArticle(&model.Article{SourceUrl: "javascript:alert(1)"}, feeds); shared.SourceUrl != "" || shared.Feed != "" {
+		t.Errorf("Expected no source and feed, got %+v", shared)
+	}
+}
+
+func TestShareService_WritePage(t *testing.T) {
+	content := &model.SharedContent{
+		Title: "Reading <list>",
+		Articles: []*model.SharedArticle{
+			{Title: "One & only", SourceUrl: "https://example.com/1", Content: "<p>Kept <b>markup</b></p>"},
+			{Title: "Two", SourceUrl: "https://example.com/2", Description: "<b>escaped</b>"},
+		},
+	}
+
+	var b bytes.Buffer
+	if err := (&ShareService{}).WritePage(&b, content); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page := b.String()
+	for _, want := range []string{"<h1>Reading &lt;list&gt;</h1>", "One &amp; only", "<p>Kept <b>markup</b></p>", "<p>&lt;b&gt;escaped&lt;/b&gt;</p>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected page to contain %q, got %s", want, page)
+		}
+	}
+}
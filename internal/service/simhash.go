@@ -0,0 +1,122 @@
+package service
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+// minShingleWordLength drops short words like articles and prepositions which carry no topic
+const minShingleWordLength = 3
+
+// simHash computes a 64 bit SimHash fingerprint over the shingles of text.
+// Similar texts result in fingerprints with a small hamming distance. It returns 0 for texts without words.
+func simHash(text string) int64 {
+	shingles := textShingles(text)
+	if len(shingles) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for i, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+	return int64(fingerprint)
+}
+
+// textShingles returns the word 2-gram shingles of text, lower-cased and without short words.
+// The single words are added as well, so short, reworded titles still share enough shingles.
+func textShingles(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	words := fields[:0]
+	for _, word := range fields {
+		if len([]rune(word)) >= minShingleWordLength {
+			words = append(words, word)
+		}
+	}
+
+	shingles := make([]string, 0, 2*len(words))
+	shingles = append(shingles, words...)
+	for i := 1; i < len(words); i++ {
+		shingles = append(shingles, words[i-1]+" "+words[i])
+	}
+	return shingles
+}
+
+const (
+	// simHashBandBits is the width of the 4 bands a fingerprint is split into to find candidates in the database
+	simHashBandBits = 16
+	// simHashProbeDistance is the distance within which the values of each band are probed. Fingerprints which
+	// differ in up to 4*(simHashProbeDistance+1)-1 = 19 bits differ in at most simHashProbeDistance bits in one
+	// of the 4 bands, so every candidate within storyMaxHammingDistance is found.
+	simHashProbeDistance = 4
+)
+
+// simHashProbeMasks are the band values with at most simHashProbeDistance bits set
+var simHashProbeMasks = func() []uint64 {
+	var masks []uint64
+	for mask := uint64(0); mask < 1<<simHashBandBits; mask++ {
+		if bits.OnesCount64(mask) <= simHashProbeDistance {
+			masks = append(masks, mask)
+		}
+	}
+	return masks
+}()
+
+// simHashBands splits the fingerprint into 16 bit bands, numbered by their position like the generated
+// articles.simhash_bands column
+func simHashBands(fingerprint int64) []int64 {
+	const bandCount = 64 / simHashBandBits
+	bands := make([]int64, bandCount)
+	for i := range bands {
+		band := (uint64(fingerprint) >> (i * simHashBandBits)) & (1<<simHashBandBits - 1)
+		bands[i] = int64(i<<simHashBandBits) + int64(band)
+	}
+	return bands
+}
+
+// simHashProbes returns the band values of all fingerprints within simHashProbeDistance bits of the fingerprint
+// in any band. A candidate shares one of them if it is within 19 bits, while about 14% of unrelated
+// fingerprints do.
+func simHashProbes(fingerprint int64) []int64 {
+	bands := simHashBands(fingerprint)
+	probes := make([]int64, 0, len(bands)*len(simHashProbeMasks))
+	for _, band := range bands {
+		for _, mask := range simHashProbeMasks {
+			probes = append(probes, band^int64(mask))
+		}
+	}
+	return probes
+}
+
+// hammingDistance returns the number of differing bits of two fingerprints
+func hammingDistance(a, b int64) int {
+	return bits.OnesCount64(uint64(a ^ b))
+}
+
+// articleFingerprint returns the SimHash of the article title and description
+func articleFingerprint(article *model.Article) int64 {
+	return simHash(article.Title + " " + plainText(article.Description))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+var (
+	ErrUnknownSourceKind   = errors.New("unknown source kind")
+	ErrInvalidSourceConfig = errors.New("invalid source config")
+)
+
+// FetchMeta contains information about the fetched source itself
+// which is not part of a single article.
+type FetchMeta struct {
+	Title    string
+	Language string
+	// FeedType is the concrete format of the source, e.g. "rss", "atom" or "json".
+	FeedType string
+	// IconURL is the image of the source, empty if it has none
+	IconURL string
+}
+
+// Source fetches articles for feeds of a specific kind.
+// New ingestion types are added by implementing Source and registering it in the SourceRegistry.
+type Source interface {
+	// Kind returns the unique identifier stored in feeds.source_kind.
+	Kind() string
+	// Validate checks the feed URL and kind specific config before a feed is created or updated.
+	Validate(ctx context.Context, feedURL string, config model.SourceConfig) error
+	// Fetch reads the current articles of the feed.
+	Fetch(ctx context.Context, feed *model.Feed) ([]*model.Article, FetchMeta, error)
+}
+
+type SourceRegistry struct {
+	sources map[string]Source
+}
+
+func NewSourceRegistry(sources ...Source) *SourceRegistry {
+	registry := &SourceRegistry{sources: make(map[string]Source, len(sources))}
+	for _, source := range sources {
+		registry.Register(source)
+	}
+	return registry
+}
+
+// Register adds the source to the registry, replacing any source with the same kind.
+func (r *SourceRegistry) Register(source Source) {
+	r.sources[source.Kind()] = source
+}
+
+// Get returns the source for the given kind. An empty kind resolves to RSS.
+func (r *SourceRegistry) Get(kind string) (Source, error) {
+	kind = normalizeSourceKind(kind)
+	source, ok := r.sources[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSourceKind, kind)
+	}
+	return source, nil
+}
+
+// Kinds returns all registered source kinds sorted alphabetically.
+func (r *SourceRegistry) Kinds() []string {
+	kinds := make([]string, 0, len(r.sources))
+	for kind := range r.sources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func normalizeSourceKind(kind string) string {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	if kind == "" {
+		return model.SourceKindRSS
+	}
+	return kind
+}
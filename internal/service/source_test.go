@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/mmcdole/gofeed"
+)
+
+func TestSourceRegistry_Get(t *testing.T) {
+	registry := NewSourceRegistry(&RssArticleReader{})
+
+	t.Run("empty kind resolves to rss", func(t *testing.T) {
+		source, err := registry.Get("")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if source.Kind() != model.SourceKindRSS {
+			t.Errorf("Expected kind %q, got %q", model.SourceKindRSS, source.Kind())
+		}
+	})
+
+	t.Run("kind is case insensitive", func(t *testing.T) {
+		if _, err := registry.Get(" RSS "); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := registry.Get("mastodon")
+		if !errors.Is(err, ErrUnknownSourceKind) {
+			t.Errorf("Expected ErrUnknownSourceKind, got %v", err)
+		}
+	})
+}
+
+func TestRssArticleReader_ValidateRejectsConfig(t *testing.T) {
+	reader := &RssArticleReader{}
+	err := reader.Validate(context.Background(), "https://example.com/rss.xml", model.SourceConfig{"selector": "article"})
+	if !errors.Is(err, ErrInvalidSourceConfig) {
+		t.Errorf("Expected ErrInvalidSourceConfig, got %v", err)
+	}
+}
+
+func TestFeedIconURL(t *testing.T) {
+	tests := []struct {
+		name string
+		feed *gofeed.Feed
+		want string
+	}{
+		{"feed image", &gofeed.Feed{Image: &gofeed.Image{URL: "https://example.com/logo.png"}, Link: "https://example.com/blog"}, "https://example.com/logo.png"},
+		{"favicon of the site", &gofeed.Feed{Link: "https://example.com/blog"}, "https://example.com/favicon.ico"},
+		{"no link", &gofeed.Feed{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := feedIconURL(tt.feed); got != tt.want {
+				t.Errorf("feedIconURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var ErrInvalidStatsRequest = errors.New("invalid statistics request")
+
+// maxStatsBuckets is the maximum number of buckets of a time series
+const maxStatsBuckets = 1000
+
+// statsDefaultRanges is the range of a time series per interval if it has no from time
+var statsDefaultRanges = map[model.StatsInterval]time.Duration{
+	model.StatsIntervalHour: 48 * time.Hour,
+	model.StatsIntervalDay:  30 * 24 * time.Hour,
+	model.StatsIntervalWeek: 26 * 7 * 24 * time.Hour,
+}
+
+type StatsService struct {
+	repo     *repository.StatsRepository
+	feedRepo *repository.FeedRepository
+}
+
+func NewStatsService(repo *repository.StatsRepository, feedRepo *repository.FeedRepository) *StatsService {
+	return &StatsService{repo: repo, feedRepo: feedRepo}
+}
+
+// GetOverview returns the time series of the articles of all feeds
+func (s *StatsService) GetOverview(ctx context.Context, req model.StatsRequest, now time.Time) (*model.StatsSeries, error) {
+	return s.getSeries(ctx, req, now, nil)
+}
+
+// GetFeed returns the time series of the articles of the feed
+func (s *StatsService) GetFeed(ctx context.Context, feedID uuid.UUID, req model.StatsRequest, now time.Time) (*model.StatsSeries, error) {
+	_, err := s.feedRepo.GetByID(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed with ID %s: %w", feedID, err)
+	}
+	return s.getSeries(ctx, req, now, &feedID)
+}
+
+func (s *StatsService) getSeries(ctx context.Context, req model.StatsRequest, now time.Time, feedID *uuid.UUID) (*model.StatsSeries, error) {
+	series, err := newStatsSeries(req, now)
+	if err != nil {
+		return nil, err
+	}
+	series.FeedID = feedID
+
+	counts, err := s.repo.GetCounts(ctx, series.Interval, series.TimeZone, series.From, series.To, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statistics: %w", err)
+	}
+	addStatsCounts(series, counts)
+	return series, nil
+}
+
+// newStatsSeries validates the request and returns an empty series with a bucket for every interval.
+// From is moved back to the start of its bucket, the last bucket ends at To and may be incomplete.
+func newStatsSeries(req model.StatsRequest, now time.Time) (*model.StatsSeries, error) {
+	interval := req.Interval
+	if interval == "" {
+		interval = model.StatsIntervalDay
+	}
+	defaultRange, ok := statsDefaultRanges[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown interval %q, use hour, day or week", ErrInvalidStatsRequest, interval)
+	}
+	timeZone := req.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil || timeZone == "Local" {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidStatsRequest, timeZone)
+	}
+
+	to := now
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.Add(-defaultRange)
+	if req.From != nil {
+		from = *req.From
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidStatsRequest)
+	}
+
+	series := &model.StatsSeries{Interval: interval, TimeZone: timeZone, To: to, Buckets: []*model.StatsBucket{}}
+	start := truncateStatsBucket(from.In(location), interval)
+	series.From = start
+	for ; start.Before(to); start = nextStatsBucket(start, interval) {
+		if len(series.Buckets) == maxStatsBuckets {
+			return nil, fmt.Errorf("%w: the range has more than %d buckets, use a larger interval", ErrInvalidStatsRequest, maxStatsBuckets)
+		}
+		series.Buckets = append(series.Buckets, &model.StatsBucket{Start: start})
+	}
+	return series, nil
+}
+
+// addStatsCounts adds the counts to their buckets and to the totals
+func addStatsCounts(series *model.StatsSeries, counts []*model.StatsMetricCount) {
+	buckets := make(map[int64]*model.StatsBucket, len(series.Buckets))
+	for _, bucket := range series.Buckets {
+		buckets[bucket.Start.Unix()] = bucket
+	}
+	for _, count := range counts {
+		bucket, ok := buckets[count.Bucket.Unix()]
+		if !ok {
+			continue
+		}
+		for _, counts := range []*model.StatsCounts{&bucket.StatsCounts, &series.Totals} {
+			switch count.Metric {
+			case "published":
+				counts.Published += count.Count
+			case "ingested":
+				counts.Ingested += count.Count
+			case "read":
+				counts.Read += count.Count
+			case "saved":
+				counts.Saved += count.Count
+			}
+		}
+	}
+}
+
+// truncateStatsBucket returns the start of the bucket of t in its location like date_trunc, weeks start on Monday
+func truncateStatsBucket(t time.Time, interval model.StatsInterval) time.Time {
+	switch interval {
+	case model.StatsIntervalHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case model.StatsIntervalWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextStatsBucket(start time.Time, interval model.StatsInterval) time.Time {
+	switch interval {
+	case model.StatsIntervalHour:
+		return start.Add(time.Hour)
+	case model.StatsIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestNewStatsSeries(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 30, 0, 0, time.UTC)
+	at := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &parsed
+	}
+
+	tests := []struct {
+		name        string
+		req         model.StatsRequest
+		wantErr     bool
+		wantFrom    string
+		wantBuckets int
+	}{
+		{
+			name:        "defaults to 30 daily buckets",
+			req:         model.StatsRequest{},
+			wantFrom:    "2024-05-01T00:00:00Z",
+			wantBuckets: 31,
+		},
+		{
+			name:        "hourly buckets",
+			req:         model.StatsRequest{Interval: model.StatsIntervalHour, From: at("2024-05-31T09:15:00Z")},
+			wantFrom:    "2024-05-31T09:00:00Z",
+			wantBuckets: 4,
+		},
+		{
+			name:        "weeks start on Monday",
+			req:         model.StatsRequest{Interval: model.StatsIntervalWeek, From: at("2024-05-16T10:00:00Z")},
+			wantFrom:    "2024-05-13T00:00:00Z",
+			wantBuckets: 3,
+		},
+		{
+			name:        "days start in the time zone",
+			req:         model.StatsRequest{From: at("2024-05-29T23:00:00Z"), To: at("2024-05-31T00:00:00Z"), TimeZone: "Europe/Berlin"},
+			wantFrom:    "2024-05-30T00:00:00+02:00",
+			wantBuckets: 2,
+		},
+		{
+			name:    "unknown interval",
+			req:     model.StatsRequest{Interval: "month"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown time zone",
+			req:     model.StatsRequest{TimeZone: "Mars/Olympus"},
+			wantErr: true,
+		},
+		{
+			name:    "local time zone",
+			req:     model.StatsRequest{TimeZone: "Local"},
+			wantErr: true,
+		},
+		{
+			name:    "from after to",
+			req:     model.StatsRequest{From: at("2024-06-01T00:00:00Z")},
+			wantErr: true,
+		},
+		{
+			name:    "too many buckets",
+			req:     model.StatsRequest{Interval: model.StatsIntervalHour, From: at("2024-01-01T00:00:00Z")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := newStatsSeries(tt.req, now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStatsRequest) {
+					t.Fatalf("expected ErrInvalidStatsRequest, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := series.From.Format(time.RFC3339); got != tt.wantFrom {
+				t.Errorf("expected from %s, got %s", tt.wantFrom, got)
+			}
+			if len(series.Buckets) != tt.wantBuckets {
+				t.Errorf("expected %d buckets, got %d", tt.wantBuckets, len(series.Buckets))
+			}
+			if !series.Buckets[0].Start.Equal(series.From) {
+				t.Errorf("expected the first bucket to start at %v, got %v", series.From, series.Buckets[0].Start)
+			}
+		})
+	}
+}
+
+func TestAddStatsCounts(t *testing.T) {
+	from := time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC)
+	to := from.Add(48 * time.Hour)
+	series, err := newStatsSeries(model.StatsRequest{From: &from, To: &to}, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Postgres returns the buckets in the session time zone, they are matched by their instant
+	secondDay := from.AddDate(0, 0, 1).In(time.FixedZone("CEST", 2*60*60))
+	addStatsCounts(series, []*model.StatsMetricCount{
+		{Metric: "published", Bucket: from, Count: 3},
+		{Metric: "ingested", Bucket: from, Count: 4},
+		{Metric: "read", Bucket: secondDay, Count: 2},
+		{Metric: "saved", Bucket: secondDay, Count: 1},
+		{Metric: "published", Bucket: from.AddDate(0, 0, 5), Count: 9},
+	})
+
+	want := []model.StatsCounts{{Published: 3, Ingested: 4}, {Read: 2, Saved: 1}}
+	for i, bucket := range series.Buckets {
+		if bucket.StatsCounts != want[i] {
+			t.Errorf("bucket %d: expected %+v, got %+v", i, want[i], bucket.StatsCounts)
+		}
+	}
+	if wantTotals := (model.StatsCounts{Published: 3, Ingested: 4, Read: 2, Saved: 1}); series.Totals != wantTotals {
+		t.Errorf("expected totals %+v, got %+v", wantTotals, series.Totals)
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+const (
+	// storyClusterWindow is the maximum distance between publish dates of articles in the same story
+	storyClusterWindow = 48 * time.Hour
+	// storyMaxHammingDistance is the maximum fingerprint distance of near-duplicate articles.
+	// Reworded texts share fewer 2-gram than single word shingles, unrelated texts differ in about 32 bits.
+	storyMaxHammingDistance = 18
+	// storyMaxCandidates caps the articles compared with a new article, so busy windows don't slow down ingestion
+	storyMaxCandidates = 500
+)
+
+type StoryService struct {
+	repo *repository.StoryRepository
+}
+
+func NewStoryService(repo *repository.StoryRepository) *StoryService {
+	return &StoryService{repo: repo}
+}
+
+// AssignCluster adds a newly saved article to the story cluster of its most similar article
+// from another feed, creating a new cluster if that article isn't clustered yet.
+func (s *StoryService) AssignCluster(ctx context.Context, article *model.Article) error {
+	if article == nil || article.SimHash == 0 {
+		return nil
+	}
+
+	candidates, err := s.repo.GetClusterCandidates(ctx, article, simHashProbes(article.SimHash),
+		article.PublishedAt.Add(-storyClusterWindow), article.PublishedAt.Add(storyClusterWindow), storyMaxCandidates)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster candidates for article %s: %w", article.ID, err)
+	}
+
+	match := closestCandidate(article.SimHash, candidates)
+	if match == nil {
+		return nil
+	}
+
+	if match.StoryClusterID != nil {
+		if err := s.repo.AddToCluster(ctx, *match.StoryClusterID, article.ID); err != nil {
+			return fmt.Errorf("failed to add article %s to story cluster: %w", article.ID, err)
+		}
+		article.StoryClusterID = match.StoryClusterID
+		return nil
+	}
+
+	clusterID, err := s.repo.CreateCluster(ctx, []uuid.UUID{match.ID, article.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create story cluster for article %s: %w", article.ID, err)
+	}
+	article.StoryClusterID = &clusterID
+	return nil
+}
+
+// closestCandidate returns the candidate with the smallest hamming distance within
+// storyMaxHammingDistance, preferring already clustered candidates on equal distance.
+func closestCandidate(fingerprint int64, candidates []*model.ClusterCandidate) *model.ClusterCandidate {
+	var best *model.ClusterCandidate
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := hammingDistance(fingerprint, candidate.SimHash)
+		if distance > storyMaxHammingDistance {
+			continue
+		}
+		if best == nil || distance < bestDistance ||
+			(distance == bestDistance && best.StoryClusterID == nil && candidate.StoryClusterID != nil) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// GetPaginated returns the story clusters between from and to together with their articles
+func (s *StoryService) GetPaginated(ctx context.Context, from, to int) ([]*model.StoryCluster, error) {
+	clusters, err := s.repo.GetPaginated(ctx, from, to-from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story clusters: %w", err)
+	}
+	if len(clusters) == 0 {
+		return clusters, nil
+	}
+
+	ids := make([]uuid.UUID, len(clusters))
+	clustersByID := make(map[uuid.UUID]*model.StoryCluster, len(clusters))
+	for i, cluster := range clusters {
+		ids[i] = cluster.ID
+		cluster.Articles = []*model.Article{}
+		clustersByID[cluster.ID] = cluster
+	}
+
+	members, err := s.repo.GetMembers(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get story cluster articles: %w", err)
+	}
+	for _, article := range members {
+		if cluster, ok := clustersByID[*article.StoryClusterID]; ok {
+			cluster.Articles = append(cluster.Articles, article)
+		}
+	}
+
+	return clusters, nil
+}
+
+// CleanupClusters removes clusters whose articles were deleted
+func (s *StoryService) CleanupClusters(ctx context.Context) error {
+	err := s.repo.DeleteEmptyClusters(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to clean up story clusters: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+)
+
+func TestSimHash_NearDuplicates(t *testing.T) {
+	same := [][2]string{
+		{
+			"Earthquake of magnitude 7.1 strikes off the coast of Japan, tsunami warning issued. The quake hit early on Tuesday morning near the city of Sendai.",
+			"Magnitude 7.1 earthquake strikes off Japan coast, tsunami warning issued. Authorities said the quake hit near Sendai early Tuesday.",
+		},
+		{
+			"Erdbeben der Stärke 7,1 erschüttert Japan – Tsunami-Warnung ausgegeben. Das Beben ereignete sich am Dienstagmorgen vor der Küste bei Sendai.",
+			"Japan: Erdbeben der Stärke 7,1 vor der Küste – Behörden geben Tsunami-Warnung aus. Das Beben ereignete sich nahe Sendai.",
+		},
+	}
+	for _, pair := range same {
+		a, b := simHash(pair[0]), simHash(pair[1])
+		if d := hammingDistance(a, b); d > storyMaxHammingDistance {
+			t.Errorf("Expected near duplicates within distance %d, got %d", storyMaxHammingDistance, d)
+		}
+		if !probed(simHashProbes(a), simHashBands(b)) {
+			t.Error("Expected near duplicates to be probed")
+		}
+	}
+
+	different := [][2]string{
+		{
+			"Earthquake of magnitude 7.1 strikes off the coast of Japan, tsunami warning issued.",
+			"Apple unveils new iPhone with faster chip and a better camera at its annual event.",
+		},
+		{
+			"Bundestag beschließt Haushalt für das kommende Jahr nach langer Debatte",
+			"Wetter: Sturmtief bringt Regen und Wind in den Norden Deutschlands",
+		},
+	}
+	for _, pair := range different {
+		if d := hammingDistance(simHash(pair[0]), simHash(pair[1])); d <= storyMaxHammingDistance {
+			t.Errorf("Expected different stories above distance %d, got %d", storyMaxHammingDistance, d)
+		}
+	}
+}
+
+func TestSimHash_EmptyText(t *testing.T) {
+	if got := simHash("a, b & c"); got != 0 {
+		t.Errorf("Expected 0 for text without shingles, got %d", got)
+	}
+}
+
+func TestClosestCandidate(t *testing.T) {
+	clusterID := uuid.New()
+	fingerprint := int64(0)
+	unclustered := &model.ClusterCandidate{ID: uuid.New(), SimHash: 0b111}
+	clustered := &model.ClusterCandidate{ID: uuid.New(), SimHash: 0b111000, StoryClusterID: &clusterID}
+	farAway := &model.ClusterCandidate{ID: uuid.New(), SimHash: -1}
+
+	t.Run("prefers clustered candidate on equal distance", func(t *testing.T) {
+		got := closestCandidate(fingerprint, []*model.ClusterCandidate{unclustered, clustered, farAway})
+		if got != clustered {
+			t.Errorf("Expected clustered candidate, got %v", got)
+		}
+	})
+
+	t.Run("ignores candidates above threshold", func(t *testing.T) {
+		if got := closestCandidate(fingerprint, []*model.ClusterCandidate{farAway}); got != nil {
+			t.Errorf("Expected no candidate, got %v", got)
+		}
+	})
+}
+
+func TestTextShingles(t *testing.T) {
+	got := textShingles("Tsunami warning for the coast of Japan")
+	want := []string{"tsunami", "warning", "for", "the", "coast", "japan",
+		"tsunami warning", "warning for", "for the", "the coast", "coast japan"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected shingles %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected shingle %d to be %q, got %q", i, want[i], got[i])
+		}
+	}
+}
+
+func TestSimHashBands(t *testing.T) {
+	got := simHashBands(-0x0123456789abcdf0)
+	want := []int64{0x3210, 65536 + 0x7654, 131072 + 0xba98, 196608 + 0xfedc}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected band %d to be %d, got %d", i, want[i], got[i])
+		}
+	}
+}
+
+func TestSimHashProbes(t *testing.T) {
+	fingerprint := int64(-0x0123456789abcdf0)
+	probes := simHashProbes(fingerprint)
+
+	// 18 bits spread as evenly as possible over the bands
+	if !probed(probes, simHashBands(fingerprint^0x000f001f001f000f)) {
+		t.Error("Expected candidate at distance 18 to be probed")
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		candidate := fingerprint
+		for _, bit := range rng.Perm(64)[:storyMaxHammingDistance] {
+			candidate ^= 1 << bit
+		}
+		if !probed(probes, simHashBands(candidate)) {
+			t.Fatalf("Expected candidate %x at distance %d to be probed",
+				uint64(candidate), hammingDistance(fingerprint, candidate))
+		}
+	}
+
+	if probed(probes, simHashBands(fingerprint^0x001f001f001f001f)) {
+		t.Error("Expected candidate differing in 5 bits of every band not to be probed")
+	}
+}
+
+// probed reports whether one of the bands is among the probes
+func probed(probes, bands []int64) bool {
+	for _, probe := range probes {
+		for _, band := range bands {
+			if probe == band {
+				return true
+			}
+		}
+	}
+	return false
+}
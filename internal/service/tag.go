@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var (
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrArticleNotFound  = errors.New("article not found")
+	ErrInvalidTagName   = errors.New("invalid tag name")
+	ErrDuplicateTagName = errors.New("tag name already exists")
+	ErrInvalidTagMerge  = errors.New("invalid tag merge")
+)
+
+// maxTagNameLength is the longest tag name in characters
+const maxTagNameLength = 50
+
+type TagService struct {
+	repo        *repository.TagRepository
+	articleRepo *repository.ArticleRepository
+	ruleService *RuleService
+}
+
+func NewTagService(repo *repository.TagRepository, articleRepo *repository.ArticleRepository, ruleService *RuleService) *TagService {
+	return &TagService{repo: repo, articleRepo: articleRepo, ruleService: ruleService}
+}
+
+// GetAll returns all tags with their number of articles, sorted by name
+func (s *TagService) GetAll(ctx context.Context) ([]*model.Tag, error) {
+	tags, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (s *TagService) GetByID(ctx context.Context, id uuid.UUID) (*model.Tag, error) {
+	tag, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag with ID %s: %w", id, err)
+	}
+	return tag, nil
+}
+
+func (s *TagService) Create(ctx context.Context, req *model.CreateTagRequest) (*model.Tag, error) {
+	name, err := s.validateTagName(ctx, req.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &model.Tag{
+		ID:        uuid.New(),
+		Name:      name,
+		CreatedAt: time.Now(),
+	}
+	createdTag, err := s.repo.Create(ctx, tag)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateTagName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return createdTag, nil
+}
+
+// Rename changes the name of a tag. Renaming to the name of another tag is rejected, merge the tags instead.
+func (s *TagService) Rename(ctx context.Context, id uuid.UUID, req *model.UpdateTagRequest) (*model.Tag, error) {
+	name, err := s.validateTagName(ctx, req.Name, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.Rename(ctx, id, name)
+	if repository.IsUniqueViolation(err) {
+		return nil, ErrDuplicateTagName
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename tag with ID %s: %w", id, err)
+	}
+	if !found {
+		return nil, ErrTagNotFound
+	}
+	return s.GetByID(ctx, id)
+}
+
+func (s *TagService) Delete(ctx context.Context, id uuid.UUID) error {
+	found, err := s.repo.Delete(ctx, id, s.ruleService.TagRewrite([]uuid.UUID{id}, nil))
+	if err != nil {
+		return fmt.Errorf("failed to delete tag with ID %s: %w", id, err)
+	}
+	if !found {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// Merge moves the articles of the source tags to the target tag and deletes the source tags
+func (s *TagService) Merge(ctx context.Context, targetID uuid.UUID, req *model.MergeTagsRequest) (*model.Tag, error) {
+	if len(req.SourceIDs) == 0 {
+		return nil, fmt.Errorf("%w: sourceIds cannot be empty", ErrInvalidTagMerge)
+	}
+	if _, err := s.GetByID(ctx, targetID); err != nil {
+		return nil, err
+	}
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == targetID {
+			return nil, fmt.Errorf("%w: a tag cannot be merged into itself", ErrInvalidTagMerge)
+		}
+		if _, err := s.GetByID(ctx, sourceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Merge(ctx, targetID, req.SourceIDs, s.ruleService.TagRewrite(req.SourceIDs, &targetID)); err != nil {
+		return nil, fmt.Errorf("failed to merge tags into %s: %w", targetID, err)
+	}
+	return s.GetByID(ctx, targetID)
+}
+
+func (s *TagService) AddToArticle(ctx context.Context, articleID, tagID uuid.UUID) error {
+	if err := s.checkArticleAndTag(ctx, articleID, tagID); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddToArticle(ctx, articleID, tagID); err != nil {
+		return fmt.Errorf("failed to tag article %s: %w", articleID, err)
+	}
+	return nil
+}
+
+func (s *TagService) RemoveFromArticle(ctx context.Context, articleID, tagID uuid.UUID) error {
+	if err := s.checkArticleAndTag(ctx, articleID, tagID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveFromArticle(ctx, articleID, tagID); err != nil {
+		return fmt.Errorf("failed to untag article %s: %w", articleID, err)
+	}
+	return nil
+}
+
+func (s *TagService) checkArticleAndTag(ctx context.Context, articleID, tagID uuid.UUID) error {
+	_, err := s.articleRepo.GetByID(ctx, articleID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get article with ID %s: %w", articleID, err)
+	}
+
+	_, err = s.GetByID(ctx, tagID)
+	return err
+}
+
+// validateTagName returns the trimmed name if it is valid and not used by a tag other than excludeID
+func (s *TagService) validateTagName(ctx context.Context, name string, excludeID *uuid.UUID) (string, error) {
+	name = normalizeName(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: name cannot be empty", ErrInvalidTagName)
+	}
+	if utf8.RuneCountInString(name) > maxTagNameLength {
+		return "", fmt.Errorf("%w: name cannot be longer than %d characters", ErrInvalidTagName, maxTagNameLength)
+	}
+
+	exists, err := s.repo.IsNameExists(ctx, name, excludeID)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for duplicate tag name: %w", err)
+	}
+	if exists {
+		return "", ErrDuplicateTagName
+	}
+	return name, nil
+}
+
+// normalizeName trims a tag, category or collection name and collapses inner whitespace
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
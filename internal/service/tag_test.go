@@ -0,0 +1,16 @@
+package service
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"  reading list ":    "reading list",
+		"machine\t learning": "machine learning",
+		"   ":                "",
+	}
+	for input, want := range tests {
+		if got := normalizeName(input); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
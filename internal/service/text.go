@@ -0,0 +1,123 @@
+package service
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements are the elements which separate their text from the surrounding text
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+	"dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// plainText strips HTML tags from s and collapses all whitespace to single spaces.
+// Text inside script and style elements is dropped. Block elements are separated by a space, inline elements
+// are not, so punctuation stays attached to the word before it.
+func plainText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	var sb strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	skipDepth := 0
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(sb.String()), " ")
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			if isSkippedTag(string(name)) {
+				skipDepth++
+			}
+			if blockElements[string(name)] {
+				sb.WriteByte(' ')
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if isSkippedTag(string(name)) && skipDepth > 0 {
+				skipDepth--
+			}
+			if blockElements[string(name)] {
+				sb.WriteByte(' ')
+			}
+		case html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if blockElements[string(name)] {
+				sb.WriteByte(' ')
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				sb.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+func isSkippedTag(name string) bool {
+	return name == "script" || name == "style"
+}
+
+// markdownText converts HTML to Markdown. Block elements become paragraphs separated by a blank line, list
+// items start with a dash and headings with ###. Links to http(s) URLs are kept, other markup is dropped.
+func markdownText(s string) string {
+	var paragraphs []string
+	var current strings.Builder
+	flush := func() {
+		if text := strings.Join(strings.Fields(current.String()), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		current.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			current.WriteString(n.Data)
+			return
+		case html.CommentNode, html.DoctypeNode:
+			return
+		case html.ElementNode:
+			if droppedElements[n.Data] {
+				return
+			}
+		}
+
+		block := n.Type == html.ElementNode && blockElements[n.Data]
+		if block {
+			flush()
+		}
+		switch n.Data {
+		case "li":
+			current.WriteString("- ")
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			current.WriteString("### ")
+		}
+		href := ""
+		if n.Data == "a" {
+			href = resolveSanitizedURL(attribute(n, "href"), nil, false)
+		}
+		if href != "" {
+			current.WriteString("[")
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if href != "" {
+			current.WriteString("](" + href + ")")
+		}
+		if block {
+			flush()
+		}
+	}
+	for _, n := range parseArticleHTML(s) {
+		walk(n)
+	}
+	flush()
+	return strings.Join(paragraphs, "\n\n")
+}
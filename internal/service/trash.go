@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lucasg04/fyrss-server/internal/model"
+	"github.com/lucasg04/fyrss-server/internal/repository"
+)
+
+var ErrNotInTrash = errors.New("not in the trash")
+
+type TrashService struct {
+	repo *repository.TrashRepository
+	// purgeAfter is the grace period after which trashed feeds and articles are deleted permanently
+	purgeAfter time.Duration
+}
+
+func NewTrashService(repo *repository.TrashRepository, purgeAfter time.Duration) *TrashService {
+	return &TrashService{repo: repo, purgeAfter: purgeAfter}
+}
+
+// Get returns the trashed feeds and a page of the trashed articles, most recently trashed first
+func (s *TrashService) Get(ctx context.Context, page model.PageRequest) (*model.TrashPage, error) {
+	if page.Offset > 0 || page.IncludeTotal {
+		return nil, fmt.Errorf("%w: the trash only supports cursor and limit", ErrInvalidPagination)
+	}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	var after *model.Article
+	if page.Cursor != "" {
+		var err error
+		after, err = decodeTrashCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	feeds, err := s.repo.GetFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed feeds: %w", err)
+	}
+	// one more article than requested tells whether there is a next page
+	articles, err := s.repo.GetArticles(ctx, after, page.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trashed articles: %w", err)
+	}
+
+	result := &model.TrashPage{Feeds: feeds, Articles: articles, PurgeAfterDays: int(s.purgeAfter.Hours() / 24)}
+	if len(articles) > page.Limit {
+		result.Articles = articles[:page.Limit]
+		result.NextCursor = encodeTrashCursor(result.Articles[page.Limit-1])
+	}
+	return result, nil
+}
+
+func (s *TrashService) RestoreFeed(ctx context.Context, id uuid.UUID) error {
+	found, err := s.repo.RestoreFeed(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore feed %s: %w", id, err)
+	}
+	if !found {
+		return fmt.Errorf("feed %s is %w", id, ErrNotInTrash)
+	}
+	return nil
+}
+
+func (s *TrashService) RestoreArticle(ctx context.Context, id uuid.UUID) error {
+	found, err := s.repo.RestoreArticle(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore article %s: %w", id, err)
+	}
+	if !found {
+		return fmt.Errorf("article %s is %w", id, ErrNotInTrash)
+	}
+	return nil
+}
+
+// Purge permanently deletes the feeds and articles trashed longer than the grace period
+func (s *TrashService) Purge(ctx context.Context) (*model.TrashPurgeResult, error) {
+	result, err := s.repo.Purge(ctx, time.Now().Add(-s.purgeAfter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return result, nil
+}